@@ -5,27 +5,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 )
 
 // SearchPages queries the Notion search API and returns page IDs.
 // The limit parameter restricts the number of page IDs returned (0 means no limit).
 func (c *Client) SearchPages(ctx context.Context, req NotionSearchRequest, limit int) ([]string, error) {
-	bts, _ := json.Marshal(req)
-	resp, err := c.request(ctx, http.MethodPost, "/v1/search", bytes.NewReader(bts))
+	sr, err := c.Search(ctx, req)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("notion search failed: status=%d body=%s", resp.StatusCode, string(body))
-	}
-	var sr NotionSearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&sr); err != nil {
-		return nil, fmt.Errorf("failed to decode search response: %w", err)
-	}
 	ids := make([]string, 0, limit)
 	for _, raw := range sr.Results {
 		var ref NotionPageRef
@@ -43,17 +32,39 @@ func (c *Client) SearchPages(ctx context.Context, req NotionSearchRequest, limit
 	return ids, nil
 }
 
-// GetPage fetches metadata for a Notion page by its ID.
+// Search runs a single request against the Notion search API and returns the
+// raw page and database objects. Pass the response's NextCursor as the
+// request's StartCursor to fetch the next page of results.
+func (c *Client) Search(ctx context.Context, req NotionSearchRequest) (*NotionSearchResponse, error) {
+	bts, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal search request: %w", err)
+	}
+	resp, err := c.request(ctx, http.MethodPost, "/v1/search", bytes.NewReader(bts))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError("notion search", resp)
+	}
+	var sr NotionSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&sr); err != nil {
+		return nil, fmt.Errorf("failed to decode search response: %w", err)
+	}
+	return &sr, nil
+}
+
+// GetPage fetches metadata for a Notion page by its ID or URL.
 func (c *Client) GetPage(ctx context.Context, pageID string) (*NotionPage, error) {
-	path := "/v1/pages/" + pageID
+	path := "/v1/pages/" + resolveID(pageID)
 	resp, err := c.request(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("get page failed: status=%d body=%s", resp.StatusCode, string(body))
+		return nil, apiError("get page", resp)
 	}
 	var pg NotionPage
 	if err := json.NewDecoder(resp.Body).Decode(&pg); err != nil {
@@ -62,9 +73,29 @@ func (c *Client) GetPage(ctx context.Context, pageID string) (*NotionPage, error
 	return &pg, nil
 }
 
-// QueryDatabase runs a query against a Notion database and returns the raw response.
+// GetBlock fetches a single block by its ID, including its parent. A URL
+// with a block anchor ("#<block-id>") fetches the linked block.
+func (c *Client) GetBlock(ctx context.Context, blockID string) (map[string]any, error) {
+	path := "/v1/blocks/" + resolveBlockID(blockID)
+	resp, err := c.request(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError("get block", resp)
+	}
+	var block map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&block); err != nil {
+		return nil, fmt.Errorf("failed to decode block: %w", err)
+	}
+	return block, nil
+}
+
+// QueryDatabase runs a query against a Notion database, given by its ID or
+// URL, and returns the raw response.
 func (c *Client) QueryDatabase(ctx context.Context, databaseID string, req NotionDatabaseQueryRequest) (*NotionDatabaseQueryResponse, error) {
-	path := "/v1/databases/" + databaseID + "/query"
+	path := "/v1/databases/" + resolveID(databaseID) + "/query"
 	bts, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal query request: %w", err)
@@ -75,8 +106,7 @@ func (c *Client) QueryDatabase(ctx context.Context, databaseID string, req Notio
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("database query failed: status=%d body=%s", resp.StatusCode, string(body))
+		return nil, apiError("database query", resp)
 	}
 	var out NotionDatabaseQueryResponse
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -84,3 +114,103 @@ func (c *Client) QueryDatabase(ctx context.Context, databaseID string, req Notio
 	}
 	return &out, nil
 }
+
+// GetDatabase fetches a Notion database object, given by its ID or URL,
+// including its property schema.
+func (c *Client) GetDatabase(ctx context.Context, databaseID string) (*NotionDatabase, error) {
+	path := "/v1/databases/" + resolveID(databaseID)
+	resp, err := c.request(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError("get database", resp)
+	}
+	var db NotionDatabase
+	if err := json.NewDecoder(resp.Body).Decode(&db); err != nil {
+		return nil, fmt.Errorf("failed to decode database: %w", err)
+	}
+	return &db, nil
+}
+
+// maxBlockChildren is the number of blocks the API accepts in one request.
+const maxBlockChildren = 100
+
+// CreatePage creates a page or database row. Children beyond the number the
+// API accepts at once are appended in further requests.
+func (c *Client) CreatePage(ctx context.Context, req NotionCreatePageRequest) (*NotionPage, error) {
+	children := req.Children
+	if len(children) > maxBlockChildren {
+		req.Children = children[:maxBlockChildren]
+	}
+	bts, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal page: %w", err)
+	}
+	resp, err := c.request(ctx, http.MethodPost, "/v1/pages", bytes.NewReader(bts))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError("create page", resp)
+	}
+	var pg NotionPage
+	if err := json.NewDecoder(resp.Body).Decode(&pg); err != nil {
+		return nil, fmt.Errorf("failed to decode page: %w", err)
+	}
+	if len(children) > maxBlockChildren {
+		if err := c.AppendBlockChildren(ctx, pg.ID, children[maxBlockChildren:]); err != nil {
+			return &pg, err
+		}
+	}
+	return &pg, nil
+}
+
+// UpdatePageProperties sets properties of a page, given by its ID or URL.
+// Properties not in props are left unchanged.
+func (c *Client) UpdatePageProperties(ctx context.Context, pageID string, props map[string]any) (*NotionPage, error) {
+	bts, err := json.Marshal(map[string]any{"properties": props})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal properties: %w", err)
+	}
+	resp, err := c.request(ctx, http.MethodPatch, "/v1/pages/"+resolveID(pageID), bytes.NewReader(bts))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError("update page", resp)
+	}
+	var pg NotionPage
+	if err := json.NewDecoder(resp.Body).Decode(&pg); err != nil {
+		return nil, fmt.Errorf("failed to decode page: %w", err)
+	}
+	return &pg, nil
+}
+
+// AppendBlockChildren adds blocks after the last child of a page or block,
+// given by its ID or URL, in batches the API accepts.
+func (c *Client) AppendBlockChildren(ctx context.Context, blockID string, children []map[string]any) error {
+	path := "/v1/blocks/" + resolveBlockID(blockID) + "/children"
+	for len(children) > 0 {
+		batch := children[:min(len(children), maxBlockChildren)]
+		children = children[len(batch):]
+		bts, err := json.Marshal(map[string]any{"children": batch})
+		if err != nil {
+			return fmt.Errorf("failed to marshal blocks: %w", err)
+		}
+		resp, err := c.request(ctx, http.MethodPatch, path, bytes.NewReader(bts))
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := apiError("append blocks", resp)
+			resp.Body.Close()
+			return err
+		}
+		resp.Body.Close()
+	}
+	return nil
+}
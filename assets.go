@@ -0,0 +1,161 @@
+package notion
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/openai/notion-go-agents/internal/atomicfile"
+)
+
+// AssetStore persists files hosted by Notion, whose download URLs expire after
+// about an hour, and returns a stable location to link to instead.
+type AssetStore interface {
+	// Put stores the content read from r under key and returns the URL or
+	// path that rendered Markdown should link to. Keys are slash-separated
+	// relative paths that are stable for a given block.
+	Put(ctx context.Context, key string, r io.Reader) (string, error)
+}
+
+// AssetStoreKeyer is implemented by asset stores whose links depend on their
+// configuration, such as DirAssetStore. Cached block trees record the stored
+// links, so stores that return different keys are cached apart; stores that
+// do not implement it are all treated alike.
+type AssetStoreKeyer interface {
+	// CacheKey returns a stable description of the store's configuration.
+	CacheKey() string
+}
+
+// WithAssetStore makes the converter download Notion-hosted images and files
+// into store and rewrite their links to the stored location. Externally
+// hosted files are linked as-is. Files that fail to download keep their
+// original, expiring URL; use WithAssetErrorHandler to learn which.
+func WithAssetStore(store AssetStore) ConverterOption {
+	return func(c *NotionMarkdownConverter) {
+		c.assets = store
+	}
+}
+
+// WithAssetErrorHandler sets a function called with the block ID and error
+// for each file that could not be copied to the AssetStore. The conversion
+// itself does not fail; the block keeps linking to Notion's expiring URL.
+func WithAssetErrorHandler(fn func(blockID string, err error)) ConverterOption {
+	return func(c *NotionMarkdownConverter) {
+		c.assetErrors = fn
+	}
+}
+
+// DirAssetStore is an AssetStore that writes files below a local directory.
+type DirAssetStore struct {
+	dir     string
+	baseURL string
+}
+
+// NewDirAssetStore returns an AssetStore that writes files below dir and
+// links to them as baseURL joined with the file's key. A typical setup writes
+// to "out/assets" and links with the relative baseURL "assets".
+func NewDirAssetStore(dir, baseURL string) *DirAssetStore {
+	return &DirAssetStore{dir: dir, baseURL: baseURL}
+}
+
+// CacheKey identifies the store's directory and base URL in cache keys.
+func (s *DirAssetStore) CacheKey() string {
+	return "dir " + s.dir + " " + s.baseURL
+}
+
+// Put writes the file atomically to its key below the store's directory.
+func (s *DirAssetStore) Put(ctx context.Context, key string, r io.Reader) (string, error) {
+	clean := path.Clean("/" + key)[1:]
+	if clean == "" {
+		return "", fmt.Errorf("invalid asset key %q", key)
+	}
+	dst := filepath.Join(s.dir, filepath.FromSlash(clean))
+	if err := atomicfile.Write(dst, r); err != nil {
+		return "", fmt.Errorf("failed to store asset: %w", err)
+	}
+	return path.Join(s.baseURL, clean), nil
+}
+
+var mediaLabels = map[string]string{
+	"video": "Video",
+	"audio": "Audio",
+	"pdf":   "PDF",
+	"file":  "File",
+}
+
+// fileObjectURL returns the URL of a Notion file object and whether the file
+// is hosted by Notion (and so expires).
+func fileObjectURL(data map[string]any) (string, bool) {
+	fileType, _ := data["type"].(string)
+	obj, _ := data[fileType].(map[string]any)
+	u, _ := obj["url"].(string)
+	return u, fileType == "file"
+}
+
+// fileNameFromURL returns the unescaped last path segment of a URL.
+func fileNameFromURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return rawURL
+	}
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return name
+}
+
+// storeAsset copies a Notion-hosted file referenced by an image or file block
+// into the converter's AssetStore and returns its stable location, or "" when
+// the block has no hosted file or the copy fails. Failures are reported to the
+// asset error handler.
+func (c *NotionMarkdownConverter) storeAsset(ctx context.Context, block map[string]any) string {
+	blockType, _ := block["type"].(string)
+	if blockType != "image" && mediaLabels[blockType] == "" {
+		return ""
+	}
+	data, _ := block[blockType].(map[string]any)
+	fileURL, hosted := fileObjectURL(data)
+	id, _ := block["id"].(string)
+	if !hosted || fileURL == "" || id == "" {
+		return ""
+	}
+	loc, err := c.copyAsset(ctx, id, blockType, fileURL)
+	if err != nil {
+		if c.assetErrors != nil {
+			c.assetErrors(id, err)
+		}
+		return ""
+	}
+	return loc
+}
+
+func (c *NotionMarkdownConverter) copyAsset(ctx context.Context, id, blockType, fileURL string) (string, error) {
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(fileNameFromURL(fileURL))
+	if name == "" || name == ".." || strings.Contains(name, "://") {
+		name = blockType
+	}
+	key := NormalizeID(id) + "/" + name
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to download asset: %w", err)
+	}
+	resp, err := c.client.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download asset: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download asset: status=%d", resp.StatusCode)
+	}
+	return c.assets.Put(ctx, key, resp.Body)
+}
@@ -0,0 +1,60 @@
+package notion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openai/notion-go-agents/internal/notiontest"
+)
+
+func TestConvertPageToMarkdownMedia(t *testing.T) {
+	srv := notiontest.New(t)
+	srv.SetFile("/secure/ws/diagram.png", "png-bytes")
+	srv.SetChildren("root",
+		notiontest.Block("img-1", "image", map[string]any{
+			"type":    "file",
+			"file":    map[string]any{"url": "https://files.example.com/secure/ws/diagram.png?X-Amz-Signature=abc", "expiry_time": "2024-01-01T01:00:00.000Z"},
+			"caption": notiontest.Text("Architecture"),
+		}),
+		notiontest.Block("vid-1", "video", map[string]any{
+			"type":     "external",
+			"external": map[string]any{"url": "https://videos.example.com/demo.mp4"},
+			"caption":  []any{},
+		}),
+		notiontest.Block("pdf-1", "pdf", map[string]any{
+			"type":    "file",
+			"file":    map[string]any{"url": "https://files.example.com/secure/ws/missing.pdf?X-Amz-Signature=abc"},
+			"name":    "Spec v2.pdf",
+			"caption": notiontest.Text("Final draft"),
+		}),
+	)
+
+	dir := t.TempDir()
+	failed := map[string]error{}
+	conv := NewNotionMarkdownConverter(newTestClient(srv),
+		WithAssetStore(NewDirAssetStore(dir, "assets")),
+		WithAssetErrorHandler(func(blockID string, err error) { failed[blockID] = err }),
+	)
+	got, err := conv.ConvertPageToMarkdown(context.Background(), "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "![Architecture](assets/img1/diagram.png)\n\n" +
+		"[Video: demo.mp4](https://videos.example.com/demo.mp4)\n\n" +
+		"[PDF: Spec v2.pdf](https://files.example.com/secure/ws/missing.pdf?X-Amz-Signature=abc) — Final draft"
+	if got != want {
+		t.Fatalf("unexpected markdown:\n%s\nwant:\n%s", got, want)
+	}
+	if len(failed) != 1 || failed["pdf-1"] == nil {
+		t.Fatalf("unexpected asset errors %v", failed)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "img1", "diagram.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "png-bytes" {
+		t.Fatalf("unexpected asset content %q", data)
+	}
+}
@@ -0,0 +1,344 @@
+package notion
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/openai/notion-go-agents/internal/atomicfile"
+)
+
+// Cache stores block trees and rendered Markdown between calls so unchanged
+// pages are not fetched again. Each entry records the last_edited_time of the
+// page it was built from and is only used while the page still reports that
+// time, as returned by GetPage, a search or a database query.
+//
+// Implementations must be safe for concurrent use. Caching is best effort:
+// a Cache that fails to store an entry should drop it silently.
+type Cache interface {
+	// Get returns the entry stored under key, if any.
+	Get(key string) (CacheEntry, bool)
+	// Set stores entry under key, replacing any previous entry.
+	Set(key string, entry CacheEntry)
+}
+
+// CacheEntry is a cached value and the page version it was built from.
+type CacheEntry struct {
+	// Version is the last_edited_time of the page the data belongs to.
+	Version string `json:"version"`
+	Data    []byte `json:"data"`
+}
+
+// WithCache makes GetPageContent, the search helpers and converters created
+// for the client reuse block trees and Markdown from cache while a page's
+// last_edited_time is unchanged. Rendered Markdown is keyed by the converter
+// options used, and block trees by the options that affect fetching, so one
+// cache can serve differently configured converters.
+//
+// Inlined child pages, child database rows and the titles of linked pages are
+// part of the cached content and are refreshed only when the page itself is
+// edited. Notion reports last_edited_time to the minute, so an edit made in
+// the same minute as the page was cached may be missed until the next one.
+func WithCache(cache Cache) ClientOption {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// MemoryCache is a Cache that keeps the most recently used entries in memory.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewMemoryCache returns a Cache holding up to maxEntries entries, evicting the
+// least recently used one when full. A maxEntries of 0 or less means no limit.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry stored under key and marks it as recently used.
+func (m *MemoryCache) Get(key string) (CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return CacheEntry{}, false
+	}
+	m.order.MoveToFront(el)
+	return el.Value.(*memoryCacheItem).entry, true
+}
+
+// Set stores entry under key, evicting the least recently used entry if the
+// cache is full.
+func (m *MemoryCache) Set(key string, entry CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.entries[key]; ok {
+		el.Value.(*memoryCacheItem).entry = entry
+		m.order.MoveToFront(el)
+		return
+	}
+	m.entries[key] = m.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+	if m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+}
+
+// Len returns the number of entries in the cache.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// DirCache is a Cache that stores each entry as a file in a local directory,
+// so cached pages survive restarts. It never evicts entries.
+type DirCache struct {
+	dir string
+}
+
+// NewDirCache returns a Cache that stores entries below dir, creating the
+// directory when the first entry is written.
+func NewDirCache(dir string) *DirCache {
+	return &DirCache{dir: dir}
+}
+
+func (d *DirCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get reads the entry stored under key. Missing or unreadable files are
+// reported as a miss.
+func (d *DirCache) Get(key string) (CacheEntry, bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return CacheEntry{}, false
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return CacheEntry{}, false
+	}
+	return entry, true
+}
+
+// Set writes the entry stored under key atomically.
+func (d *DirCache) Set(key string, entry CacheEntry) {
+	_ = d.write(key, entry)
+}
+
+func (d *DirCache) write(key string, entry CacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(d.path(key), data)
+}
+
+// cachedTree is the stored form of a page's block tree, including the titles
+// of the pages it links to so rendering it needs no further lookups.
+type cachedTree struct {
+	Nodes  []cachedNode      `json:"nodes"`
+	Titles map[string]string `json:"titles,omitempty"`
+}
+
+type cachedNode struct {
+	Block     map[string]any  `json:"block"`
+	Children  []cachedNode    `json:"children,omitempty"`
+	Database  *cachedDatabase `json:"database,omitempty"`
+	AssetURL  string          `json:"asset_url,omitempty"`
+	PageDepth int             `json:"page_depth,omitempty"`
+}
+
+type cachedDatabase struct {
+	Columns   []string   `json:"columns"`
+	Rows      [][]string `json:"rows"`
+	Truncated bool       `json:"truncated,omitempty"`
+	Linked    bool       `json:"linked,omitempty"`
+	Err       string     `json:"error,omitempty"`
+}
+
+func (c *NotionMarkdownConverter) cache() Cache {
+	if c.client == nil {
+		return nil
+	}
+	return c.client.cache
+}
+
+// cacheKeys returns the keys under which the block tree and the rendered
+// Markdown of a page are cached for this converter's options.
+func (c *NotionMarkdownConverter) cacheKeys(pageID string) (tree, markdown string) {
+	fetch := fmt.Sprintf("depth=%d nodes=%d databases=%d pages=%d/%d/%d assets=%q",
+		c.maxDepth, c.maxNodes, c.childDatabaseRows, c.childPages, c.maxChildPageDepth, c.maxChildPages, assetStoreKey(c.assets))
+	render := fmt.Sprintf("frontmatter=%t colors=%d toggles=%d columns=%d tables=%t/%d/%d",
+		c.frontMatter, c.colors, c.toggles, c.columns, c.alignTables, c.maxTableColumnWidth, c.wideTableColumns)
+	id := NormalizeID(pageID)
+	return "tree/" + id + "/" + fingerprint(fetch), "markdown/" + id + "/" + fingerprint(fetch+" "+render)
+}
+
+// assetStoreKey identifies an asset store in cache keys: by its CacheKey
+// when it is an AssetStoreKeyer, and otherwise only by whether a store is
+// set.
+func assetStoreKey(store AssetStore) string {
+	switch store := store.(type) {
+	case nil:
+		return ""
+	case AssetStoreKeyer:
+		return store.CacheKey()
+	}
+	return "custom"
+}
+
+func fingerprint(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
+
+// writeCachedPage writes a page using cached Markdown or a cached block tree
+// when they match the page's version, and fills the cache otherwise.
+func (c *NotionMarkdownConverter) writeCachedPage(ctx context.Context, w io.Writer, cache Cache, pageID string, pg *NotionPage) error {
+	version := pg.LastEditedTime
+	_, mdKey := c.cacheKeys(pageID)
+	if entry, ok := cache.Get(mdKey); ok && entry.Version == version {
+		_, err := w.Write(entry.Data)
+		return err
+	}
+	nodes, err := c.cachedBlockTree(ctx, cache, pageID, version)
+	if err != nil {
+		return err
+	}
+	md := c.RenderMarkdown(nodes)
+	if md == "" {
+		md = "(no textual content)"
+	}
+	if c.frontMatter {
+		md = FrontMatter(pg) + "\n" + md
+	}
+	cache.Set(mdKey, CacheEntry{Version: version, Data: []byte(md)})
+	_, err = io.WriteString(w, md)
+	return err
+}
+
+// cachedBlockTree returns the block tree of a page at the given version from
+// cache, fetching and storing it when it is missing or stale.
+func (c *NotionMarkdownConverter) cachedBlockTree(ctx context.Context, cache Cache, pageID, version string) ([]BlockNode, error) {
+	treeKey, _ := c.cacheKeys(pageID)
+	if entry, ok := cache.Get(treeKey); ok && entry.Version == version {
+		if nodes, ok := c.decodeTree(entry.Data); ok {
+			return nodes, nil
+		}
+	}
+	nodes, err := c.GetBlockTree(ctx, pageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block tree: %w", err)
+	}
+	if data, err := c.encodeTree(nodes); err == nil {
+		cache.Set(treeKey, CacheEntry{Version: version, Data: data})
+	}
+	return nodes, nil
+}
+
+func (c *NotionMarkdownConverter) encodeTree(nodes []BlockNode) ([]byte, error) {
+	tree := cachedTree{Nodes: toCachedNodes(nodes), Titles: make(map[string]string)}
+	c.titlesMu.Lock()
+	collectTitles(nodes, c.titles, tree.Titles)
+	c.titlesMu.Unlock()
+	return json.Marshal(tree)
+}
+
+func (c *NotionMarkdownConverter) decodeTree(data []byte) ([]BlockNode, bool) {
+	var tree cachedTree
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, false
+	}
+	c.titlesMu.Lock()
+	for id, title := range tree.Titles {
+		if _, ok := c.titles[id]; !ok {
+			c.titles[id] = title
+		}
+	}
+	c.titlesMu.Unlock()
+	nodes := fromCachedNodes(tree.Nodes)
+	if nodes == nil {
+		nodes = []BlockNode{}
+	}
+	return nodes, true
+}
+
+// collectTitles copies the looked-up titles of pages linked from nodes.
+func collectTitles(nodes []BlockNode, from, to map[string]string) {
+	for _, node := range nodes {
+		for _, ref := range linkedObjects(node.Block) {
+			if title, ok := from[ref.id]; ok {
+				to[ref.id] = title
+			}
+		}
+		collectTitles(node.Children, from, to)
+	}
+}
+
+func toCachedNodes(nodes []BlockNode) []cachedNode {
+	if len(nodes) == 0 {
+		return nil
+	}
+	out := make([]cachedNode, len(nodes))
+	for i, node := range nodes {
+		out[i] = cachedNode{
+			Block:     node.Block,
+			Children:  toCachedNodes(node.Children),
+			AssetURL:  node.assetURL,
+			PageDepth: node.pageDepth,
+		}
+		if db := node.database; db != nil {
+			out[i].Database = &cachedDatabase{Columns: db.columns, Rows: db.rows, Truncated: db.truncated, Linked: db.linked}
+			if db.err != nil {
+				out[i].Database.Err = db.err.Error()
+			}
+		}
+	}
+	return out
+}
+
+func fromCachedNodes(nodes []cachedNode) []BlockNode {
+	if len(nodes) == 0 {
+		return nil
+	}
+	out := make([]BlockNode, len(nodes))
+	for i, node := range nodes {
+		out[i] = BlockNode{
+			Block:     node.Block,
+			Children:  fromCachedNodes(node.Children),
+			assetURL:  node.AssetURL,
+			pageDepth: node.PageDepth,
+		}
+		if db := node.Database; db != nil {
+			out[i].database = &databaseTable{columns: db.Columns, rows: db.Rows, truncated: db.Truncated, linked: db.Linked}
+			if db.Err != "" {
+				out[i].database.err = errors.New(db.Err)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,139 @@
+package notion
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/openai/notion-go-agents/internal/notiontest"
+)
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewMemoryCache(2)
+	c.Set("a", CacheEntry{Version: "1", Data: []byte("A")})
+	c.Set("b", CacheEntry{Version: "1", Data: []byte("B")})
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	c.Set("c", CacheEntry{Version: "1", Data: []byte("C")})
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if e, ok := c.Get("a"); !ok || string(e.Data) != "A" {
+		t.Fatalf("unexpected entry for a: %+v %v", e, ok)
+	}
+	if c.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", c.Len())
+	}
+}
+
+func TestDirCache(t *testing.T) {
+	dir := t.TempDir()
+	NewDirCache(dir).Set("markdown/abc", CacheEntry{Version: "v1", Data: []byte("# Hi")})
+	e, ok := NewDirCache(dir).Get("markdown/abc")
+	if !ok || e.Version != "v1" || string(e.Data) != "# Hi" {
+		t.Fatalf("unexpected entry: %+v %v", e, ok)
+	}
+	if _, ok := NewDirCache(dir).Get("markdown/other"); ok {
+		t.Fatal("expected a miss for an unknown key")
+	}
+}
+
+func TestGetPageContentCache(t *testing.T) {
+	srv := notiontest.New(t)
+	srv.AddPage(notiontest.Page("root", "Runbook"))
+	srv.AddPage(notiontest.Page("target", "Escalation"))
+	srv.SetChildren("root",
+		notiontest.Block("p1", "paragraph", map[string]any{"rich_text": []any{
+			notiontest.TextItem("Page "),
+			mentionItem("page", map[string]any{"id": "target"}, "Untitled"),
+		}}),
+	)
+	ctx := context.Background()
+	dir := t.TempDir()
+	client := NewClient("secret", "", WithHTTPClient(srv.HTTPClient()), WithCache(NewDirCache(dir)))
+
+	first, err := GetPageContent(ctx, client, "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := GetPageContent(ctx, client, "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Markdown != second.Markdown || first.Markdown != "Page [Escalation](https://www.notion.so/target)" {
+		t.Fatalf("unexpected markdown: %q then %q", first.Markdown, second.Markdown)
+	}
+	if n := srv.Requests("GET /v1/blocks/root/children"); n != 1 {
+		t.Fatalf("expected blocks to be fetched once, got %d", n)
+	}
+
+	// Other render options reuse the cached block tree and linked titles.
+	other := NewClient("secret", "", WithHTTPClient(srv.HTTPClient()), WithCache(NewDirCache(dir)))
+	if _, err := GetPageContent(ctx, other, "root", WithColors(ColorHTML)); err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.Requests("GET /v1/blocks/root/children"); n != 1 {
+		t.Fatalf("expected the cached block tree to be reused, got %d fetches", n)
+	}
+	if n := srv.Requests("GET /v1/pages/target"); n != 1 {
+		t.Fatalf("expected the linked title to be cached, got %d lookups", n)
+	}
+
+	// Search results carry last_edited_time, so no page lookups are needed.
+	pages := srv.Requests("GET /v1/pages/root")
+	results, err := SearchWorkspace(ctx, client, NotionSearchRequest{Query: "runbook"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Markdown != first.Markdown {
+		t.Fatalf("unexpected search results: %+v", results)
+	}
+	if n := srv.Requests("GET /v1/pages/root"); n != pages {
+		t.Fatalf("expected no page lookups for search results, got %d", n-pages)
+	}
+
+	edited := notiontest.Page("root", "Runbook")
+	edited["last_edited_time"] = "2024-02-01T00:00:00.000Z"
+	srv.AddPage(edited)
+	srv.SetChildren("root", notiontest.TextBlock("p2", "paragraph", "Updated"))
+	third, err := GetPageContent(ctx, client, "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Markdown != "Updated" {
+		t.Fatalf("expected an edited page to be refetched, got %q", third.Markdown)
+	}
+}
+
+// testAssetStore holds state that must not leak into cache keys.
+type testAssetStore struct {
+	count int
+}
+
+func (s *testAssetStore) Put(ctx context.Context, key string, r io.Reader) (string, error) {
+	s.count++
+	return key, nil
+}
+
+func TestCacheKeysAssetStore(t *testing.T) {
+	keys := func(opts ...ConverterOption) string {
+		tree, _ := NewNotionMarkdownConverter(NewClient("secret", ""), opts...).cacheKeys("page")
+		return tree
+	}
+	none := keys()
+	dir := keys(WithAssetStore(NewDirAssetStore("out/assets", "assets")))
+	if dir == none {
+		t.Fatal("asset store not part of cache key")
+	}
+	if again := keys(WithAssetStore(NewDirAssetStore("out/assets", "assets"))); again != dir {
+		t.Fatalf("equal stores have different keys: %s != %s", again, dir)
+	}
+	if other := keys(WithAssetStore(NewDirAssetStore("out/assets", "/static"))); other == dir {
+		t.Fatal("stores linking differently share a key")
+	}
+	custom := keys(WithAssetStore(&testAssetStore{count: 1}))
+	if again := keys(WithAssetStore(&testAssetStore{count: 2})); again != custom {
+		t.Fatalf("custom store key depends on its state: %s != %s", again, custom)
+	}
+}
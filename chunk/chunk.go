@@ -0,0 +1,337 @@
+// Package chunk splits Notion pages into retrieval-sized pieces.
+//
+// Chunks are cut from a page's block tree rather than its flattened Markdown:
+// headings start new chunks and set each chunk's breadcrumb, whole blocks are
+// packed up to a size budget, tables and code blocks are never split, and
+// every chunk records the IDs of the blocks it was rendered from so answers
+// can cite exact locations.
+package chunk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/internal/mdsplit"
+)
+
+// Chunk is a piece of a page.
+type Chunk struct {
+	PageID string
+	// Index is the position of the chunk within its page, from 0.
+	Index int
+	// Breadcrumb holds the page title followed by the headings the chunk is
+	// under, outermost first.
+	Breadcrumb []string
+	// Text is the chunk's Markdown.
+	Text string
+	// BlockIDs are the IDs of the blocks rendered into Text, in order. A
+	// block's nested children are part of it unless they were too large and
+	// were chunked on their own.
+	BlockIDs []string
+}
+
+// ContextText returns the chunk's text preceded by its breadcrumb, which
+// gives embeddings and prompts the context the text was taken from.
+func (c Chunk) ContextText() string {
+	if len(c.Breadcrumb) == 0 {
+		return c.Text
+	}
+	return strings.Join(c.Breadcrumb, " > ") + "\n\n" + c.Text
+}
+
+// URL returns a link to the chunk's first block within its page, for citing
+// it, or to the page when the chunk has no block IDs.
+func (c Chunk) URL() string {
+	if len(c.BlockIDs) == 0 {
+		return notion.BlockURL(c.PageID, "")
+	}
+	return notion.BlockURL(c.PageID, c.BlockIDs[0])
+}
+
+// Option configures a Chunker.
+type Option func(*Chunker)
+
+// WithMaxChars sets the size budget of a chunk in characters. Blocks are
+// packed into a chunk until the next one would exceed it; a single block
+// larger than the budget, such as a long table, gets a chunk of its own.
+// The default is 2000.
+func WithMaxChars(n int) Option {
+	return func(c *Chunker) {
+		if n > 0 {
+			c.maxChars = n
+		}
+	}
+}
+
+// WithOverlap repeats up to n characters of whole blocks from the end of a
+// chunk at the start of the next one when a section is split for size.
+// Chunks do not overlap across headings. The default is no overlap.
+func WithOverlap(n int) Option {
+	return func(c *Chunker) {
+		if n >= 0 {
+			c.overlap = n
+		}
+	}
+}
+
+// WithConverterOptions sets the options used by Page to fetch and render
+// blocks.
+func WithConverterOptions(opts ...notion.ConverterOption) Option {
+	return func(c *Chunker) {
+		c.converterOptions = opts
+	}
+}
+
+// Chunker splits block trees into chunks.
+type Chunker struct {
+	maxChars         int
+	overlap          int
+	converterOptions []notion.ConverterOption
+}
+
+// New returns a Chunker configured by opts.
+func New(opts ...Option) *Chunker {
+	c := &Chunker{maxChars: 2000}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Page fetches a page and its blocks and splits them into chunks.
+func (c *Chunker) Page(ctx context.Context, client *notion.Client, pageID string) ([]Chunk, error) {
+	pg, err := client.GetPage(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+	conv := notion.NewNotionMarkdownConverter(client, c.converterOptions...)
+	nodes, err := conv.GetBlockTree(ctx, pageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block tree: %w", err)
+	}
+	return c.Split(conv, pg.ID, notion.ExtractNotionTitle(pg.Properties), nodes), nil
+}
+
+// Split chunks a block tree fetched with conv.GetBlockTree, rendering blocks
+// with conv. The title starts every chunk's breadcrumb.
+func (c *Chunker) Split(conv *notion.NotionMarkdownConverter, pageID, title string, nodes []notion.BlockNode) []Chunk {
+	p := packer{c: c, pageID: pageID, title: title}
+	for _, u := range c.units(conv, nodes) {
+		p.add(u)
+	}
+	p.flush(nil)
+	return p.chunks
+}
+
+// SplitMarkdown chunks already rendered Markdown, such as the Markdown of a
+// notion.PageContent, where the block tree is not at hand. Blocks are taken to
+// be separated by blank lines outside code fences, and "#", "##" and "###"
+// lines are headings. Chunks cut from Markdown have no block IDs; see
+// SplitPageContent.
+func (c *Chunker) SplitMarkdown(pageID, title, md string) []Chunk {
+	return c.splitMarkdown(pageID, title, md, nil)
+}
+
+// SplitPageContent chunks the Markdown of a page like SplitMarkdown. When the
+// page was converted with notion.WithSourceMap, chunks get the IDs of the
+// blocks their lines were rendered from.
+func (c *Chunker) SplitPageContent(pc *notion.PageContent) []Chunk {
+	return c.splitMarkdown(pc.ID, pc.Title, pc.Markdown, pc.SourceMap)
+}
+
+func (c *Chunker) splitMarkdown(pageID, title, md string, sm notion.SourceMap) []Chunk {
+	p := packer{c: c, pageID: pageID, title: title}
+	for _, u := range markdownUnits(md, sm) {
+		p.add(u)
+	}
+	p.flush(nil)
+	return p.chunks
+}
+
+// unit is a run of Markdown that is never split across chunks.
+type unit struct {
+	ids  []string
+	text string
+	size int
+	// level is 1 to 3 for a heading, which starts a new chunk.
+	level int
+	title string
+}
+
+func (c *Chunker) newUnit(ids []string, text string) unit {
+	return unit{ids: ids, text: text, size: utf8.RuneCountInString(text)}
+}
+
+// units turns blocks into units. Runs of list items of the same kind are kept
+// together while they fit the budget. Oversized blocks other than tables and
+// code are split into the block itself and its children.
+func (c *Chunker) units(conv *notion.NotionMarkdownConverter, nodes []notion.BlockNode) []unit {
+	var out []unit
+	for i := 0; i < len(nodes); i++ {
+		node := nodes[i]
+		blockType, _ := node.Block["type"].(string)
+		id, _ := node.Block["id"].(string)
+		if level := headingLevel(blockType); level > 0 {
+			own := notion.BlockNode{Block: node.Block}
+			u := c.newUnit([]string{id}, conv.RenderMarkdown([]notion.BlockNode{own}))
+			u.level = level
+			data, _ := node.Block[blockType].(map[string]any)
+			rt, _ := data["rich_text"].([]any)
+			u.title = strings.TrimSpace(notion.PlainText(rt))
+			out = append(out, u)
+			// Children of toggleable headings belong to the heading's section.
+			out = append(out, c.units(conv, node.Children)...)
+			continue
+		}
+		if isListItem(blockType) {
+			j := i + 1
+			for j < len(nodes) && nodes[j].Block["type"] == blockType {
+				j++
+			}
+			if j-i > 1 {
+				if u := c.newUnit(blockIDs(nodes[i:j]), conv.RenderMarkdown(nodes[i:j])); u.size <= c.maxChars {
+					out = append(out, u)
+					i = j - 1
+					continue
+				}
+			}
+		}
+		u := c.newUnit([]string{id}, conv.RenderMarkdown([]notion.BlockNode{node}))
+		if u.size == 0 {
+			continue
+		}
+		if u.size > c.maxChars && len(node.Children) > 0 && blockType != "table" && blockType != "code" {
+			own := notion.BlockNode{Block: node.Block}
+			if text := conv.RenderMarkdown([]notion.BlockNode{own}); text != "" {
+				out = append(out, c.newUnit([]string{id}, text))
+			}
+			out = append(out, c.units(conv, node.Children)...)
+			continue
+		}
+		out = append(out, u)
+	}
+	return out
+}
+
+// markdownUnits splits Markdown into paragraphs and headings.
+func markdownUnits(md string, sm notion.SourceMap) []unit {
+	var out []unit
+	for _, part := range mdsplit.Split(md) {
+		// ids holds the blocks the part's lines were rendered from.
+		var ids []string
+		for i := range part.Lines {
+			if id := sm.BlockAtLine(part.Line + i); id != "" && (len(ids) == 0 || ids[len(ids)-1] != id) {
+				ids = append(ids, id)
+			}
+		}
+		text := part.Text()
+		if part.Level > 0 {
+			text = part.Lines[0]
+		}
+		if text == "" {
+			continue
+		}
+		out = append(out, unit{ids: ids, text: text, size: utf8.RuneCountInString(text), level: part.Level, title: part.Title})
+	}
+	return out
+}
+
+// packer accumulates units into chunks.
+type packer struct {
+	c        *Chunker
+	pageID   string
+	title    string
+	headings [3]string
+	cur      []unit
+	size     int
+	chunks   []Chunk
+}
+
+func (p *packer) add(u unit) {
+	if u.level > 0 {
+		p.flush(nil)
+		p.headings[u.level-1] = u.title
+		for i := u.level; i < len(p.headings); i++ {
+			p.headings[i] = ""
+		}
+	}
+	if len(p.cur) > 0 && p.size+2+u.size > p.c.maxChars {
+		p.flush(&u)
+	}
+	if len(p.cur) > 0 {
+		p.size += 2
+	}
+	p.cur = append(p.cur, u)
+	p.size += u.size
+}
+
+// flush ends the current chunk. When next is set the section continues with
+// next, and the tail of the chunk is carried over as overlap as long as it
+// leaves room for next.
+func (p *packer) flush(next *unit) {
+	if len(p.cur) == 0 {
+		return
+	}
+	chunk := Chunk{PageID: p.pageID, Index: len(p.chunks)}
+	if p.title != "" {
+		chunk.Breadcrumb = append(chunk.Breadcrumb, p.title)
+	}
+	for _, h := range p.headings {
+		if h != "" {
+			chunk.Breadcrumb = append(chunk.Breadcrumb, h)
+		}
+	}
+	texts := make([]string, len(p.cur))
+	for i, u := range p.cur {
+		texts[i] = u.text
+		chunk.BlockIDs = append(chunk.BlockIDs, u.ids...)
+	}
+	chunk.Text = strings.Join(texts, "\n\n")
+	p.chunks = append(p.chunks, chunk)
+
+	var tail []unit
+	size := 0
+	if next != nil && p.c.overlap > 0 {
+		for i := len(p.cur) - 1; i > 0; i-- {
+			grown := size + p.cur[i].size
+			if len(tail) > 0 {
+				grown += 2
+			}
+			if grown > p.c.overlap || grown+2+next.size > p.c.maxChars {
+				break
+			}
+			tail = append([]unit{p.cur[i]}, tail...)
+			size = grown
+		}
+	}
+	p.cur = tail
+	p.size = size
+}
+
+func headingLevel(blockType string) int {
+	switch blockType {
+	case "heading_1":
+		return 1
+	case "heading_2":
+		return 2
+	case "heading_3":
+		return 3
+	}
+	return 0
+}
+
+func isListItem(blockType string) bool {
+	return blockType == "bulleted_list_item" || blockType == "numbered_list_item" || blockType == "to_do"
+}
+
+func blockIDs(nodes []notion.BlockNode) []string {
+	ids := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		id, _ := node.Block["id"].(string)
+		ids = append(ids, id)
+	}
+	return ids
+}
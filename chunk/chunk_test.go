@@ -0,0 +1,122 @@
+package chunk
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/internal/notiontest"
+)
+
+func node(id, blockType, text string) notion.BlockNode {
+	return notion.BlockNode{Block: notiontest.TextBlock(id, blockType, text)}
+}
+
+func summarize(chunks []Chunk) string {
+	var lines []string
+	for _, c := range chunks {
+		lines = append(lines, strings.Join(c.Breadcrumb, " > ")+" | "+strings.Join(c.BlockIDs, ",")+" | "+strings.ReplaceAll(c.Text, "\n", "⏎"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestSplitHeadings(t *testing.T) {
+	nodes := []notion.BlockNode{
+		node("p0", "paragraph", "Intro"),
+		node("h1", "heading_1", "Setup"),
+		node("p1", "paragraph", "Install it."),
+		node("h2", "heading_2", "Linux"),
+		node("l1", "numbered_list_item", "Download"),
+		node("l2", "numbered_list_item", "Run"),
+		node("h3", "heading_1", "Usage"),
+		node("p2", "paragraph", "Call it."),
+	}
+	conv := notion.NewNotionMarkdownConverter(nil)
+	got := summarize(New().Split(conv, "page", "Runbook", nodes))
+	want := "Runbook | p0 | Intro\n" +
+		"Runbook > Setup | h1,p1 | # Setup⏎⏎Install it.\n" +
+		"Runbook > Setup > Linux | h2,l1,l2 | ## Linux⏎⏎1. Download⏎2. Run\n" +
+		"Runbook > Usage | h3,p2 | # Usage⏎⏎Call it."
+	if got != want {
+		t.Fatalf("unexpected chunks:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSplitBudgetAndOverlap(t *testing.T) {
+	nodes := []notion.BlockNode{
+		node("a", "paragraph", strings.Repeat("a", 30)),
+		node("b", "paragraph", strings.Repeat("b", 30)),
+		node("c", "paragraph", strings.Repeat("c", 30)),
+		notion.BlockNode{Block: notiontest.Block("code", "code", map[string]any{
+			"rich_text": notiontest.Text(strings.Repeat("x := 1\n", 30)),
+			"language":  "go",
+		})},
+		node("d", "paragraph", strings.Repeat("d", 30)),
+	}
+	conv := notion.NewNotionMarkdownConverter(nil)
+	chunks := New(WithMaxChars(70), WithOverlap(40)).Split(conv, "page", "", nodes)
+	var ids []string
+	for _, c := range chunks {
+		ids = append(ids, strings.Join(c.BlockIDs, ","))
+	}
+	if got := strings.Join(ids, " | "); got != "a,b | b,c | code | d" {
+		t.Fatalf("unexpected chunk blocks: %s", got)
+	}
+	if !strings.HasPrefix(chunks[2].Text, "```go\n") || !strings.HasSuffix(chunks[2].Text, "x := 1\n```") {
+		t.Fatalf("expected the code block to stay intact:\n%s", chunks[2].Text)
+	}
+	if chunks[3].Index != 3 || chunks[3].ContextText() != strings.Repeat("d", 30) {
+		t.Fatalf("unexpected last chunk: %+v", chunks[3])
+	}
+}
+
+func TestPage(t *testing.T) {
+	srv := notiontest.New(t)
+	srv.AddPage(notiontest.Page("root", "Runbook"))
+	srv.SetChildren("root",
+		notiontest.TextBlock("h", "heading_2", "Escalation"),
+		notiontest.TextBlock("p", "paragraph", "Page the lead."),
+	)
+	client := notion.NewClient("secret", "", notion.WithHTTPClient(srv.HTTPClient()))
+	chunks, err := New().Page(context.Background(), client, "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 1 || chunks[0].ContextText() != "Runbook > Escalation\n\n## Escalation\n\nPage the lead." {
+		t.Fatalf("unexpected chunks: %+v", chunks)
+	}
+}
+
+func TestSplitMarkdown(t *testing.T) {
+	md := "Intro\n\n## Setup\n\n```sh\nmake\n\n# not a heading\n```\n\n- one\n- two"
+	got := summarize(New().SplitMarkdown("page", "Runbook", md))
+	want := "Runbook |  | Intro\n" +
+		"Runbook > Setup |  | ## Setup⏎⏎```sh⏎make⏎⏎# not a heading⏎```⏎⏎- one⏎- two"
+	if got != want {
+		t.Fatalf("unexpected chunks:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSplitPageContent(t *testing.T) {
+	pc := &notion.PageContent{
+		ID:       "page",
+		Title:    "Runbook",
+		Markdown: "Intro\n\n## Setup\n\n- one\n- two",
+		SourceMap: notion.SourceMap{
+			{BlockID: "p0", StartLine: 1, EndLine: 1},
+			{BlockID: "h1", StartLine: 3, EndLine: 3},
+			{BlockID: "l1", StartLine: 5, EndLine: 5},
+			{BlockID: "l2", StartLine: 6, EndLine: 6},
+		},
+	}
+	chunks := New().SplitPageContent(pc)
+	want := "Runbook | p0 | Intro\n" +
+		"Runbook > Setup | h1,l1,l2 | ## Setup⏎⏎- one⏎- two"
+	if got := summarize(chunks); got != want {
+		t.Fatalf("unexpected chunks:\n%s\nwant:\n%s", got, want)
+	}
+	if got := chunks[1].URL(); got != "https://www.notion.so/page#h1" {
+		t.Fatalf("unexpected URL %q", got)
+	}
+}
@@ -2,6 +2,8 @@ package notion
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -16,6 +18,7 @@ type Client struct {
 	apiKey        string
 	notionVersion string
 	timeout       time.Duration
+	cache         Cache
 }
 
 // NewClient creates a Notion API client.
@@ -78,3 +81,48 @@ func (c *Client) request(ctx context.Context, method, path string, body io.Reade
 	}
 	return c.httpClient.Do(req)
 }
+
+// APIError is returned, possibly wrapped, when the Notion API answers a
+// request with an error status. Use errors.As to inspect it.
+type APIError struct {
+	// Op names the request that failed, such as "get page".
+	Op string
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Code is Notion's error code, such as "object_not_found" or
+	// "rate_limited", when the response has one.
+	Code string
+	// Message is Notion's description of the error, when the response has
+	// one.
+	Message string
+	// Body is the response body as received.
+	Body string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s failed: status=%d body=%s", e.Op, e.StatusCode, e.Body)
+}
+
+// IsNotFound reports whether err is an APIError for an object that does not
+// exist or is not shared with the integration.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == "object_not_found" || apiErr.Code == "" && apiErr.StatusCode == http.StatusNotFound
+}
+
+// apiError reads the error response of the request named op.
+func apiError(op string, resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	e := &APIError{Op: op, StatusCode: resp.StatusCode, Body: string(body)}
+	var payload struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		e.Code, e.Message = payload.Code, payload.Message
+	}
+	return e
+}
@@ -0,0 +1,65 @@
+// Command notion-mcp serves a Notion workspace to AI assistants over the
+// Model Context Protocol.
+//
+// By default it speaks MCP over stdin and stdout, for assistants that launch
+// it as a subprocess. With -http it serves the streamable HTTP transport at
+// /mcp instead. The integration token is read from NOTION_API_KEY. The tools
+// only read the workspace unless -write is given.
+//
+//	notion-mcp
+//	notion-mcp -http 127.0.0.1:8080
+//	notion-mcp -write
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/mcp"
+	"github.com/openai/notion-go-agents/tools"
+)
+
+func main() {
+	addr := flag.String("http", "", "serve the streamable HTTP transport on this address instead of stdio")
+	write := flag.Bool("write", false, "also serve tools that create and edit pages")
+	flag.Parse()
+
+	// Stdout carries the protocol; logs go to stderr.
+	log.SetOutput(os.Stderr)
+	apiKey := os.Getenv("NOTION_API_KEY")
+	if apiKey == "" {
+		log.Fatal("NOTION_API_KEY not set")
+	}
+	client := notion.NewClient(apiKey, os.Getenv("NOTION_VERSION"))
+	var opts []mcp.Option
+	if *write {
+		opts = append(opts, mcp.WithToolset(tools.New(client, tools.WithWriteTools())))
+	}
+	srv := mcp.NewServer(client, opts...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *addr == "" {
+		if err := srv.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil && ctx.Err() == nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", srv)
+	hs := &http.Server{Addr: *addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		hs.Close()
+	}()
+	log.Printf("serving MCP on http://%s/mcp", *addr)
+	if err := hs.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
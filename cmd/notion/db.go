@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	notion "github.com/openai/notion-go-agents"
+)
+
+// stringList is a flag that may be repeated.
+type stringList []string
+
+func (l *stringList) String() string     { return strings.Join(*l, ", ") }
+func (l *stringList) Set(s string) error { *l = append(*l, s); return nil }
+
+func dbQuery(ctx context.Context, client *notion.Client, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("db query", flag.ContinueOnError)
+	var where stringList
+	fs.Var(&where, "where", "filter such as 'Status=Done', or a Notion API filter as JSON; repeat to combine with and")
+	format := fs.String("format", "table", "output format: table, json or csv")
+	limit := fs.Int("limit", 100, "maximum number of rows")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+	switch *format {
+	case "table", "json", "csv":
+	default:
+		return fmt.Errorf("%w: unknown format %q", errUsage, *format)
+	}
+	id := resolveID(positional[0])
+	db, err := client.GetDatabase(ctx, id)
+	if err != nil {
+		return err
+	}
+	req := notion.NotionDatabaseQueryRequest{}
+	if req.Filter, err = whereFilter(where, db.Properties); err != nil {
+		return err
+	}
+
+	var rows []*notion.NotionPage
+	for len(rows) < *limit {
+		req.PageSize = min(*limit-len(rows), 100)
+		res, err := client.QueryDatabase(ctx, id, req)
+		if err != nil {
+			return err
+		}
+		for _, raw := range res.Results {
+			var pg notion.NotionPage
+			if err := json.Unmarshal(raw, &pg); err == nil && pg.Object == "page" {
+				rows = append(rows, &pg)
+			}
+		}
+		if !res.HasMore || res.NextCursor == "" {
+			break
+		}
+		req.StartCursor = res.NextCursor
+	}
+
+	columns := notion.DatabaseSchema(db)
+	switch *format {
+	case "json":
+		out := make([]map[string]any, 0, len(rows))
+		for _, pg := range rows {
+			props := make(map[string]any, len(pg.Properties))
+			for name, v := range pg.Properties {
+				prop, _ := v.(map[string]any)
+				props[name] = notion.PropertyValue(prop)
+			}
+			out = append(out, map[string]any{"id": pg.ID, "url": pg.URL, "properties": props})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(append([]string{"id"}, columnNames(columns)...))
+		for _, pg := range rows {
+			cw.Write(append([]string{pg.ID}, rowTexts(pg, columns)...))
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(columnNames(columns), "\t"))
+	for _, pg := range rows {
+		cells := rowTexts(pg, columns)
+		for i, cell := range cells {
+			cells[i] = strings.Join(strings.Fields(cell), " ")
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func dbSchema(ctx context.Context, client *notion.Client, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("db schema", flag.ContinueOnError)
+	samples := fs.Int("samples", 3, "number of sample rows to show")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+	desc, err := notion.DescribeDatabaseSchema(ctx, client, positional[0], *samples)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, desc)
+	return err
+}
+
+func columnNames(columns []notion.SchemaProperty) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+func rowTexts(pg *notion.NotionPage, columns []notion.SchemaProperty) []string {
+	cells := make([]string, len(columns))
+	for i, c := range columns {
+		prop, _ := pg.Properties[c.Name].(map[string]any)
+		cells[i] = notion.PropertyText(prop)
+	}
+	return cells
+}
+
+// whereOperators are the comparison operators of a --where expression,
+// longest first so that "!=" is not read as "=".
+var whereOperators = []string{"!=", ">=", "<=", "=", ">", "<", "~"}
+
+// whereFilter builds a database query filter from --where expressions. An
+// expression is either a Notion API filter as JSON, or "<property><op>
+// <value>" where op is =, !=, >, >=, <, <= or ~ (contains); the property's
+// type in schema decides which Notion condition is used. Several
+// expressions are combined with "and".
+func whereFilter(exprs []string, schema map[string]any) (map[string]any, error) {
+	var filters []any
+	for _, expr := range exprs {
+		f, err := parseWhere(expr, schema)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	switch len(filters) {
+	case 0:
+		return nil, nil
+	case 1:
+		return filters[0].(map[string]any), nil
+	}
+	return map[string]any{"and": filters}, nil
+}
+
+func parseWhere(expr string, schema map[string]any) (map[string]any, error) {
+	if strings.HasPrefix(strings.TrimSpace(expr), "{") {
+		var f map[string]any
+		if err := json.Unmarshal([]byte(expr), &f); err != nil {
+			return nil, fmt.Errorf("failed to parse filter %q: %w", expr, err)
+		}
+		return f, nil
+	}
+	at, op := -1, ""
+	for _, o := range whereOperators {
+		if i := strings.Index(expr, o); i > 0 && (at < 0 || i < at) {
+			at, op = i, o
+		}
+	}
+	if at < 0 {
+		return nil, fmt.Errorf("%w: filter %q has no operator", errUsage, expr)
+	}
+	name := strings.TrimSpace(expr[:at])
+	value := strings.TrimSpace(expr[at+len(op):])
+	prop, ok := schema[name].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown property %q; see db schema", errUsage, name)
+	}
+	typ, _ := prop["type"].(string)
+
+	var conditions map[string]string
+	var v any = value
+	switch typ {
+	case "title", "rich_text", "url", "email", "phone_number":
+		conditions = map[string]string{"=": "equals", "!=": "does_not_equal", "~": "contains"}
+	case "select", "status":
+		conditions = map[string]string{"=": "equals", "!=": "does_not_equal"}
+	case "multi_select", "people", "relation":
+		conditions = map[string]string{"=": "contains", "~": "contains", "!=": "does_not_contain"}
+	case "number":
+		conditions = map[string]string{"=": "equals", "!=": "does_not_equal", ">": "greater_than", ">=": "greater_than_or_equal_to", "<": "less_than", "<=": "less_than_or_equal_to"}
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not a number", errUsage, value)
+		}
+		v = n
+	case "checkbox":
+		conditions = map[string]string{"=": "equals", "!=": "does_not_equal"}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not true or false", errUsage, value)
+		}
+		v = b
+	case "date", "created_time", "last_edited_time":
+		conditions = map[string]string{"=": "equals", ">": "after", ">=": "on_or_after", "<": "before", "<=": "on_or_before"}
+	default:
+		return nil, fmt.Errorf("%w: cannot filter %s property %q; pass a JSON filter", errUsage, typ, name)
+	}
+	condition, ok := conditions[op]
+	if !ok {
+		return nil, fmt.Errorf("%w: operator %s is not supported for %s property %q", errUsage, op, typ, name)
+	}
+	return map[string]any{"property": name, typ: map[string]any{condition: v}}, nil
+}
@@ -0,0 +1,211 @@
+// Command notion shows what an agent sees of a Notion workspace: search
+// results, pages rendered as Markdown, HTML or plain text, database rows and
+// schemas, and Markdown exports. The integration token is read from
+// NOTION_API_KEY and the API version from NOTION_VERSION.
+//
+//	notion search <query>
+//	notion cat [--format markdown|html|text] <page-id|url>
+//	notion db query [--where 'Status=Done'] [--format table|json|csv] [--limit n] <database-id|url>
+//	notion db schema <database-id|url>
+//	notion export [<root-id|url>...] <dir>
+//
+// Flags may follow the positional arguments.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/notionexport"
+)
+
+const usage = `usage:
+  notion search [--limit n] <query>
+  notion cat [--format markdown|html|text] <page-id|url>
+  notion db query [--where expr]... [--format table|json|csv] [--limit n] <database-id|url>
+  notion db schema <database-id|url>
+  notion export [<root-id|url>...] <dir>
+`
+
+// errUsage reports a malformed command line.
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := run(ctx, os.Args[1:], os.Stdout)
+	stop()
+	os.Exit(report(err, os.Stderr))
+}
+
+// report writes err to w and returns the exit status for it. Usage errors
+// print the usage, after what was wrong when the error says, such as a bad
+// --where expression.
+func report(err error, w io.Writer) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage):
+		if err != errUsage {
+			fmt.Fprintln(w, "notion:", err)
+		}
+		fmt.Fprint(w, usage)
+		return 2
+	}
+	fmt.Fprintln(w, "notion:", err)
+	return 1
+}
+
+func run(ctx context.Context, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	apiKey := os.Getenv("NOTION_API_KEY")
+	if apiKey == "" {
+		return errors.New("NOTION_API_KEY not set")
+	}
+	client := notion.NewClient(apiKey, os.Getenv("NOTION_VERSION"))
+	switch args[0] {
+	case "search":
+		return search(ctx, client, args[1:], w)
+	case "cat":
+		return cat(ctx, client, args[1:], w)
+	case "db":
+		if len(args) < 2 {
+			return errUsage
+		}
+		switch args[1] {
+		case "query":
+			return dbQuery(ctx, client, args[2:], w)
+		case "schema":
+			return dbSchema(ctx, client, args[2:], w)
+		}
+	case "export":
+		return export(ctx, client, args[1:], w)
+	}
+	return errUsage
+}
+
+// parse parses flags that may appear before, between or after the
+// positional arguments, and returns the positional arguments.
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// resolveID returns the ID in a page or database URL, or s itself.
+func resolveID(s string) string {
+	if id, err := notion.ParseID(s); err == nil {
+		return id
+	}
+	return s
+}
+
+func search(ctx context.Context, client *notion.Client, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "maximum number of results")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return errUsage
+	}
+	sr, err := client.Search(ctx, notion.NotionSearchRequest{Query: strings.Join(positional, " "), PageSize: min(max(*limit, 1), 100)})
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, raw := range sr.Results {
+		var ref struct {
+			notion.NotionPageRef
+			Title      []any          `json:"title"`
+			Properties map[string]any `json:"properties"`
+		}
+		if err := json.Unmarshal(raw, &ref); err != nil {
+			continue
+		}
+		title := notion.ExtractNotionTitle(ref.Properties)
+		if ref.Object == "database" {
+			title = notion.PlainText(ref.Title)
+		}
+		if title == "" {
+			title = "Untitled"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", ref.ID, ref.Object, title, ref.URL)
+	}
+	return tw.Flush()
+}
+
+func cat(ctx context.Context, client *notion.Client, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("cat", flag.ContinueOnError)
+	format := fs.String("format", "markdown", "output format: markdown, html or text")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+	id := resolveID(positional[0])
+	conv := notion.NewNotionMarkdownConverter(client)
+	switch *format {
+	case "markdown", "md":
+		return conv.WritePageMarkdown(ctx, w, id)
+	case "html", "text":
+	default:
+		return fmt.Errorf("%w: unknown format %q", errUsage, *format)
+	}
+
+	pg, err := client.GetPage(ctx, id)
+	if err != nil {
+		return err
+	}
+	nodes, err := conv.GetBlockTree(ctx, id)
+	if err != nil {
+		return err
+	}
+	if *format == "text" {
+		_, err = fmt.Fprintln(w, conv.RenderText(nodes))
+		return err
+	}
+	_, err = fmt.Fprintf(w, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n%s</body>\n</html>\n",
+		html.EscapeString(notion.ExtractNotionTitle(pg.Properties)), conv.RenderHTML(nodes))
+	return err
+}
+
+func export(ctx context.Context, client *notion.Client, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	dir := args[len(args)-1]
+	var roots []string
+	for _, root := range args[:len(args)-1] {
+		roots = append(roots, resolveID(root))
+	}
+	res, err := notionexport.ExportWorkspace(ctx, client, dir, roots)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%d written, %d unchanged, %d removed\n", res.Written, res.Unchanged, res.Removed)
+	return err
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestWhereFilter(t *testing.T) {
+	schema := map[string]any{
+		"Name":     map[string]any{"type": "title"},
+		"Status":   map[string]any{"type": "status"},
+		"Points":   map[string]any{"type": "number"},
+		"Tags":     map[string]any{"type": "multi_select"},
+		"Due":      map[string]any{"type": "date"},
+		"Archived": map[string]any{"type": "checkbox"},
+	}
+	cases := map[string]string{
+		"Status=Done":    `{"property":"Status","status":{"equals":"Done"}}`,
+		"Status != Done": `{"property":"Status","status":{"does_not_equal":"Done"}}`,
+		"Points>=3":      `{"number":{"greater_than_or_equal_to":3},"property":"Points"}`,
+		"Tags=ops":       `{"multi_select":{"contains":"ops"},"property":"Tags"}`,
+		"Name~plan":      `{"property":"Name","title":{"contains":"plan"}}`,
+		"Due<2024-01-01": `{"date":{"before":"2024-01-01"},"property":"Due"}`,
+		"Archived=false": `{"checkbox":{"equals":false},"property":"Archived"}`,
+		`{"property":"Name","title":{"is_empty":true}}`: `{"property":"Name","title":{"is_empty":true}}`,
+	}
+	for expr, want := range cases {
+		f, err := whereFilter([]string{expr}, schema)
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		got, _ := json.Marshal(f)
+		if string(got) != want {
+			t.Errorf("%s: got %s, want %s", expr, got, want)
+		}
+	}
+
+	f, err := whereFilter([]string{"Status=Done", "Points>1"}, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if and, _ := f["and"].([]any); len(and) != 2 {
+		t.Fatalf("expected two and-ed filters, got %v", f)
+	}
+	for _, expr := range []string{"Owner=me", "Points=many", "Status~Do", "Status"} {
+		if _, err := whereFilter([]string{expr}, schema); err == nil {
+			t.Errorf("%s: expected an error", expr)
+		}
+	}
+}
+
+func TestParseAndResolveID(t *testing.T) {
+	fs := flag.NewFlagSet("cat", flag.ContinueOnError)
+	format := fs.String("format", "markdown", "")
+	args, err := parse(fs, []string{"https://www.notion.so/team/Roadmap-0123456789abcdef0123456789abcdef?pvs=4", "--format", "html"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *format != "html" || len(args) != 1 {
+		t.Fatalf("unexpected parse result: %q %v", *format, args)
+	}
+	if id := resolveID(args[0]); id != "01234567-89ab-cdef-0123-456789abcdef" {
+		t.Fatalf("unexpected ID %q", id)
+	}
+	if id := resolveID("guide"); id != "guide" {
+		t.Fatalf("unexpected ID %q", id)
+	}
+}
+
+func TestReport(t *testing.T) {
+	var b strings.Builder
+	_, err := whereFilter([]string{"Owner=me"}, map[string]any{})
+	if code := report(err, &b); code != 2 || !strings.HasPrefix(b.String(), "notion: invalid arguments: unknown property \"Owner\"; see db schema\nusage:") {
+		t.Fatalf("unexpected report %d: %q", code, b.String())
+	}
+	b.Reset()
+	if code := report(errUsage, &b); code != 2 || b.String() != usage {
+		t.Fatalf("unexpected report %d: %q", code, b.String())
+	}
+}
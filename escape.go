@@ -0,0 +1,142 @@
+package notion
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The helpers in this file escape plain text for the Markdown context it is
+// written into, so that a CommonMark parser reads back the original text.
+
+// EscapeText escapes s so that it reads back as literal text when written
+// into a line of Markdown, such as a paragraph or a link label.
+func EscapeText(s string) string {
+	return escapeInline(s)
+}
+
+// escapeInline escapes characters that would otherwise start emphasis, code
+// spans, links, HTML or entity references inside a line of text.
+func escapeInline(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i, r := range s {
+		switch r {
+		case '\\':
+			if next, _ := utf8.DecodeRuneInString(s[i+1:]); isASCIIPunct(next) || i+1 == len(s) {
+				b.WriteByte('\\')
+			}
+		case '*', '`', '[', ']', '<', '~':
+			b.WriteByte('\\')
+		case '_':
+			prev, _ := utf8.DecodeLastRuneInString(s[:i])
+			next, _ := utf8.DecodeRuneInString(s[i+1:])
+			if !isWordRune(prev) || !isWordRune(next) {
+				b.WriteByte('\\')
+			}
+		case '&':
+			if entityRef.MatchString(s[i:]) {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var (
+	entityRef = regexp.MustCompile(`^&(#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+	// blockStart matches line openings that a parser would read as a
+	// heading, block quote, list item or thematic break.
+	blockStart   = regexp.MustCompile(`^(#{1,6}(?:[ \t]|$)|>|[-+](?:[ \t]|$)|[0-9]{1,9}[.)](?:[ \t]|$))`)
+	setextMarker = regexp.MustCompile(`^(=+|-+)[ \t]*$`)
+)
+
+// escapeBlockStart escapes markers at the start of each line of already
+// inline-escaped text so that the text stays a paragraph.
+func escapeBlockStart(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		switch {
+		case setextMarker.MatchString(line):
+			lines[i] = "\\" + line
+		case blockStart.MatchString(line):
+			if line[0] >= '0' && line[0] <= '9' {
+				j := strings.IndexAny(line, ".)")
+				lines[i] = line[:j] + "\\" + line[j:]
+			} else {
+				lines[i] = "\\" + line
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// escapeHeading escapes a trailing '#' that would be read as the closing
+// sequence of an ATX heading.
+func escapeHeading(s string) string {
+	if strings.HasSuffix(s, "#") && !strings.HasSuffix(s, "\\#") {
+		return s[:len(s)-1] + "\\#"
+	}
+	return s
+}
+
+// escapeTableCell escapes pipes, which end a cell even inside code spans, and
+// turns line breaks, which would end the row, into <br> elements.
+func escapeTableCell(s string) string {
+	return tableCellEscaper.Replace(s)
+}
+
+var tableCellEscaper = strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>")
+
+// escapeLinkDestination percent-encodes characters that would end a link
+// destination early.
+func escapeLinkDestination(url string) string {
+	return linkDestinationEscaper.Replace(url)
+}
+
+var linkDestinationEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+
+// codeSpan wraps s in a backtick fence longer than any run of backticks it
+// contains, padding with spaces when s starts or ends with a backtick.
+func codeSpan(s string) string {
+	fence := strings.Repeat("`", longestRun(s, '`')+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// codeFence returns a fence for a fenced code block that is longer than any
+// run of backticks inside content.
+func codeFence(content string) string {
+	n := longestRun(content, '`') + 1
+	if n < 3 {
+		n = 3
+	}
+	return strings.Repeat("`", n)
+}
+
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
+
+func isASCIIPunct(r rune) bool {
+	return r < utf8.RuneSelf && unicode.IsPunct(r) || strings.ContainsRune("$+<=>^`|~", r)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
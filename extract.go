@@ -1,6 +1,10 @@
 package notion
 
-import "strings"
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
 
 // ExtractNotionTitle finds the first title-like property and returns its plain text.
 func ExtractNotionTitle(props map[string]any) string {
@@ -71,3 +75,249 @@ func SelectPrintableProperties(props map[string]any) map[string]any {
 	}
 	return out
 }
+
+// PlainText concatenates the plain_text of a rich text array.
+func PlainText(richText []any) string {
+	var b strings.Builder
+	for _, it := range richText {
+		im, _ := it.(map[string]any)
+		if pt, _ := im["plain_text"].(string); pt != "" {
+			b.WriteString(pt)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// PropertyText renders a page property value as a short human-readable string.
+// Unknown or empty values render as an empty string.
+func PropertyText(prop map[string]any) string {
+	t, _ := prop["type"].(string)
+	return propertyValueText(t, prop[t])
+}
+
+func propertyValueText(t string, v any) string {
+	switch t {
+	case "title", "rich_text":
+		arr, _ := v.([]any)
+		return PlainText(arr)
+	case "number":
+		if n, ok := v.(float64); ok {
+			return strconv.FormatFloat(n, 'f', -1, 64)
+		}
+	case "checkbox":
+		if b, ok := v.(bool); ok {
+			if b {
+				return "Yes"
+			}
+			return "No"
+		}
+	case "select", "status":
+		m, _ := v.(map[string]any)
+		name, _ := m["name"].(string)
+		return name
+	case "multi_select":
+		arr, _ := v.([]any)
+		names := make([]string, 0, len(arr))
+		for _, it := range arr {
+			m, _ := it.(map[string]any)
+			if name, _ := m["name"].(string); name != "" {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, ", ")
+	case "date":
+		m, _ := v.(map[string]any)
+		start, _ := m["start"].(string)
+		if start == "" {
+			return ""
+		}
+		end, _ := m["end"].(string)
+		tz, _ := m["time_zone"].(string)
+		return formatNotionDate(start, end, tz)
+	case "created_time", "last_edited_time":
+		s, _ := v.(string)
+		return formatNotionTime(s)
+	case "url", "email", "phone_number", "string":
+		s, _ := v.(string)
+		return s
+	case "boolean":
+		return propertyValueText("checkbox", v)
+	case "people":
+		arr, _ := v.([]any)
+		names := make([]string, 0, len(arr))
+		for _, it := range arr {
+			if name := userName(it); name != "" {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, ", ")
+	case "created_by", "last_edited_by":
+		return userName(v)
+	case "relation":
+		arr, _ := v.([]any)
+		ids := make([]string, 0, len(arr))
+		for _, it := range arr {
+			m, _ := it.(map[string]any)
+			if id, _ := m["id"].(string); id != "" {
+				ids = append(ids, id)
+			}
+		}
+		return strings.Join(ids, ", ")
+	case "files":
+		arr, _ := v.([]any)
+		names := make([]string, 0, len(arr))
+		for _, it := range arr {
+			m, _ := it.(map[string]any)
+			if name, _ := m["name"].(string); name != "" {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, ", ")
+	case "formula":
+		m, _ := v.(map[string]any)
+		ft, _ := m["type"].(string)
+		return propertyValueText(ft, m[ft])
+	case "rollup":
+		m, _ := v.(map[string]any)
+		rt, _ := m["type"].(string)
+		if rt != "array" {
+			return propertyValueText(rt, m[rt])
+		}
+		arr, _ := m["array"].([]any)
+		parts := make([]string, 0, len(arr))
+		for _, it := range arr {
+			im, _ := it.(map[string]any)
+			if s := PropertyText(im); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	case "unique_id":
+		m, _ := v.(map[string]any)
+		n, ok := m["number"].(float64)
+		if !ok {
+			return ""
+		}
+		num := strconv.FormatFloat(n, 'f', -1, 64)
+		if prefix, _ := m["prefix"].(string); prefix != "" {
+			return prefix + "-" + num
+		}
+		return num
+	case "verification":
+		m, _ := v.(map[string]any)
+		state, _ := m["state"].(string)
+		return state
+	}
+	return ""
+}
+
+func userName(v any) string {
+	m, _ := v.(map[string]any)
+	if name, _ := m["name"].(string); name != "" {
+		return name
+	}
+	id, _ := m["id"].(string)
+	return id
+}
+
+// schemaPropertyNames returns the property names of a database schema with the
+// title property first and the rest in alphabetical order.
+func schemaPropertyNames(schema map[string]any) []string {
+	names := make([]string, 0, len(schema))
+	title := ""
+	for name, v := range schema {
+		m, _ := v.(map[string]any)
+		if t, _ := m["type"].(string); t == "title" && title == "" {
+			title = name
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if title != "" {
+		names = append([]string{title}, names...)
+	}
+	return names
+}
+
+// PropertyValue converts a page property value to a plain Go value suitable
+// for serialization: strings for text, selects, statuses and unique IDs,
+// float64 for numbers, bool for checkboxes, ISO 8601 strings for dates (or a
+// map with "start" and "end" for ranges) and string slices for multi-selects,
+// people, files and relations (as page IDs). Empty values are nil.
+func PropertyValue(prop map[string]any) any {
+	t, _ := prop["type"].(string)
+	return propertyValue(t, prop[t])
+}
+
+func propertyValue(t string, v any) any {
+	switch t {
+	case "number":
+		if n, ok := v.(float64); ok {
+			return n
+		}
+		return nil
+	case "checkbox", "boolean":
+		b, _ := v.(bool)
+		return b
+	case "date":
+		m, _ := v.(map[string]any)
+		start, _ := m["start"].(string)
+		if start == "" {
+			return nil
+		}
+		if end, _ := m["end"].(string); end != "" {
+			return map[string]any{"start": start, "end": end}
+		}
+		return start
+	case "created_time", "last_edited_time":
+		s, _ := v.(string)
+		return emptyToNil(s)
+	case "multi_select", "people", "relation", "files":
+		arr, _ := v.([]any)
+		out := make([]string, 0, len(arr))
+		for _, it := range arr {
+			var s string
+			m, _ := it.(map[string]any)
+			switch t {
+			case "multi_select", "files":
+				s, _ = m["name"].(string)
+			case "people":
+				s = userName(m)
+			case "relation":
+				s, _ = m["id"].(string)
+			}
+			if s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	case "formula":
+		m, _ := v.(map[string]any)
+		ft, _ := m["type"].(string)
+		return propertyValue(ft, m[ft])
+	case "rollup":
+		m, _ := v.(map[string]any)
+		rt, _ := m["type"].(string)
+		if rt != "array" {
+			return propertyValue(rt, m[rt])
+		}
+		arr, _ := m["array"].([]any)
+		out := make([]any, 0, len(arr))
+		for _, it := range arr {
+			im, _ := it.(map[string]any)
+			if val := PropertyValue(im); val != nil {
+				out = append(out, val)
+			}
+		}
+		return out
+	}
+	return emptyToNil(propertyValueText(t, v))
+}
+
+func emptyToNil(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
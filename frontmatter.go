@@ -0,0 +1,179 @@
+package notion
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WithFrontMatter makes ConvertPageToMarkdown prepend YAML front matter built
+// by FrontMatter. It costs one extra request to fetch the page.
+func WithFrontMatter() ConverterOption {
+	return func(c *NotionMarkdownConverter) {
+		c.frontMatter = true
+	}
+}
+
+// FrontMatter renders page metadata as a YAML front matter block delimited by
+// "---" lines: the page ID, title, URL, created and last edited times, parent
+// and every non-title property converted with PropertyValue.
+func FrontMatter(pg *NotionPage) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	writeYAMLField(&b, "id", pg.ID, 0)
+	writeYAMLField(&b, "title", ExtractNotionTitle(pg.Properties), 0)
+	url := pg.PublicURL
+	if url == "" {
+		url = pg.URL
+	}
+	writeYAMLField(&b, "url", url, 0)
+	writeYAMLField(&b, "created_time", pg.CreatedTime, 0)
+	writeYAMLField(&b, "last_edited_time", pg.LastEditedTime, 0)
+	if pg.Archived {
+		writeYAMLField(&b, "archived", true, 0)
+	}
+	if parentType, _ := pg.Parent["type"].(string); parentType != "" {
+		parent := map[string]any{"type": parentType}
+		if id, ok := pg.Parent[parentType].(string); ok {
+			parent["id"] = id
+		}
+		writeYAMLField(&b, "parent", parent, 0)
+	}
+	props := make(map[string]any)
+	for name, v := range pg.Properties {
+		prop, _ := v.(map[string]any)
+		if t, _ := prop["type"].(string); t == "title" {
+			continue
+		}
+		props[name] = PropertyValue(prop)
+	}
+	if len(props) > 0 {
+		writeYAMLField(&b, "properties", props, 0)
+	}
+	b.WriteString("---\n")
+	return b.String()
+}
+
+// DatabaseFrontMatter renders database metadata as a YAML front matter block
+// laid out like FrontMatter's: the database ID, title, description, URL,
+// times, parent and the names of its properties, title first.
+func DatabaseFrontMatter(db *NotionDatabase) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	writeYAMLField(&b, "id", db.ID, 0)
+	writeYAMLField(&b, "title", PlainText(db.Title), 0)
+	if desc := PlainText(db.Description); desc != "" {
+		writeYAMLField(&b, "description", desc, 0)
+	}
+	writeYAMLField(&b, "url", db.URL, 0)
+	writeYAMLField(&b, "created_time", db.CreatedTime, 0)
+	writeYAMLField(&b, "last_edited_time", db.LastEditedTime, 0)
+	if db.Archived {
+		writeYAMLField(&b, "archived", true, 0)
+	}
+	if parentType, _ := db.Parent["type"].(string); parentType != "" {
+		parent := map[string]any{"type": parentType}
+		if id, ok := db.Parent[parentType].(string); ok {
+			parent["id"] = id
+		}
+		writeYAMLField(&b, "parent", parent, 0)
+	}
+	if names := schemaPropertyNames(db.Properties); len(names) > 0 {
+		writeYAMLField(&b, "properties", names, 0)
+	}
+	b.WriteString("---\n")
+	return b.String()
+}
+
+// writeYAMLField writes "key: value" at the given indentation, nesting maps
+// and slices in block style. Map keys are written in sorted order.
+func writeYAMLField(b *strings.Builder, key string, value any, indent int) {
+	pad := strings.Repeat("  ", indent)
+	b.WriteString(pad + yamlString(key) + ":")
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		b.WriteString("\n")
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			writeYAMLField(b, k, v[k], indent+1)
+		}
+	case []string:
+		items := make([]any, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		writeYAMLList(b, items, pad)
+	case []any:
+		writeYAMLList(b, v, pad)
+	default:
+		b.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+func writeYAMLList(b *strings.Builder, items []any, pad string) {
+	if len(items) == 0 {
+		b.WriteString(" []\n")
+		return
+	}
+	b.WriteString("\n")
+	for _, it := range items {
+		if m, ok := it.(map[string]any); ok {
+			// Ranges inside rollups are the only nested maps; keep them inline.
+			bts, _ := json.Marshal(m)
+			b.WriteString(pad + "  - " + string(bts) + "\n")
+			continue
+		}
+		b.WriteString(pad + "  - " + yamlScalar(it) + "\n")
+	}
+}
+
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return yamlString(v)
+	default:
+		bts, _ := json.Marshal(v)
+		return string(bts)
+	}
+}
+
+var (
+	yamlPlain    = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9 _./:@+?=&%-]*$`)
+	yamlReserved = regexp.MustCompile(`^(?i:true|false|yes|no|on|off|y|n|null|~)$|^[-+]?[0-9][0-9_.]*(e[-+]?[0-9]+)?$|^0[xo]`)
+)
+
+// yamlString returns s as a plain scalar when that reads back as the same
+// string, and as a double-quoted scalar otherwise. ISO dates stay plain so
+// that front matter consumers can parse them as timestamps.
+func yamlString(s string) string {
+	if isISODate(s) {
+		return s
+	}
+	if yamlPlain.MatchString(s) && !yamlReserved.MatchString(s) && !strings.Contains(s, ": ") && !strings.HasSuffix(s, ":") && !strings.HasSuffix(s, " ") {
+		return s
+	}
+	bts, _ := json.Marshal(s)
+	return string(bts)
+}
+
+var isoDate = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}(T[0-9:.]+(Z|[+-][0-9]{2}:[0-9]{2})?)?$`)
+
+func isISODate(s string) bool {
+	return isoDate.MatchString(s)
+}
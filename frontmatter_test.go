@@ -0,0 +1,49 @@
+package notion
+
+import "testing"
+
+func TestFrontMatter(t *testing.T) {
+	pg := &NotionPage{
+		NotionPageRef:  NotionPageRef{Object: "page", ID: "p1", URL: "https://www.notion.so/p1"},
+		CreatedTime:    "2024-01-02T03:04:05.000Z",
+		LastEditedTime: "2024-02-03T04:05:06.000Z",
+		Parent:         map[string]any{"type": "database_id", "database_id": "db1"},
+		Properties: map[string]any{
+			"Name":     map[string]any{"type": "title", "title": []any{map[string]any{"plain_text": "Launch: v2"}}},
+			"Status":   map[string]any{"type": "status", "status": map[string]any{"name": "In progress"}},
+			"Due":      map[string]any{"type": "date", "date": map[string]any{"start": "2024-03-01", "end": "2024-03-05"}},
+			"Tags":     map[string]any{"type": "multi_select", "multi_select": []any{map[string]any{"name": "infra"}, map[string]any{"name": "yes"}}},
+			"Blocks":   map[string]any{"type": "relation", "relation": []any{map[string]any{"id": "p2"}}},
+			"Estimate": map[string]any{"type": "number", "number": 3.5},
+			"Done":     map[string]any{"type": "checkbox", "checkbox": false},
+			"Notes":    map[string]any{"type": "rich_text", "rich_text": []any{}},
+		},
+	}
+	want := `---
+id: p1
+title: "Launch: v2"
+url: https://www.notion.so/p1
+created_time: 2024-01-02T03:04:05.000Z
+last_edited_time: 2024-02-03T04:05:06.000Z
+parent:
+  id: db1
+  type: database_id
+properties:
+  Blocks:
+    - p2
+  Done: false
+  Due:
+    end: 2024-03-05
+    start: 2024-03-01
+  Estimate: 3.5
+  Notes: null
+  Status: In progress
+  Tags:
+    - infra
+    - "yes"
+---
+`
+	if got := FrontMatter(pg); got != want {
+		t.Fatalf("unexpected front matter:\n%s\nwant:\n%s", got, want)
+	}
+}
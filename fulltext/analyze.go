@@ -0,0 +1,106 @@
+package fulltext
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// token is a word of a text: its stemmed term and the byte range of the
+// original word.
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits text into runs of letters and digits and stems them.
+func tokenize(text string) []token {
+	var out []token
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			out = append(out, token{term: stem(strings.ToLower(text[start:i])), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		out = append(out, token{term: stem(strings.ToLower(text[start:])), start: start, end: len(text)})
+	}
+	return out
+}
+
+func terms(text string) []string {
+	toks := tokenize(text)
+	out := make([]string, len(toks))
+	for i, t := range toks {
+		out[i] = t.term
+	}
+	return out
+}
+
+// stem reduces an English word to a stem shared by its inflections, so
+// "rotated", "rotates" and "rotate" all match. It handles plurals, "-ed" and
+// "-ing" in the manner of the first step of the Porter stemmer, and drops a
+// final "e"; it is not meant to produce dictionary words.
+func stem(w string) string {
+	if utf8.RuneCountInString(w) <= 3 {
+		return w
+	}
+	switch {
+	case strings.HasSuffix(w, "sses"):
+		w = w[:len(w)-2]
+	case strings.HasSuffix(w, "ies"), strings.HasSuffix(w, "ied"):
+		w = w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "ss"), strings.HasSuffix(w, "us"), strings.HasSuffix(w, "is"):
+	case strings.HasSuffix(w, "s"):
+		w = w[:len(w)-1]
+	}
+	for _, suffix := range []string{"ing", "ed"} {
+		if !strings.HasSuffix(w, suffix) {
+			continue
+		}
+		base := w[:len(w)-len(suffix)]
+		if len(base) < 3 || !strings.ContainsAny(base, "aeiouy") {
+			break
+		}
+		w = base
+		// "running" -> "run", but "called" -> "call".
+		if n := len(w); w[n-1] == w[n-2] && !strings.ContainsRune("aeioulsz", rune(w[n-1])) {
+			w = w[:n-1]
+		}
+		break
+	}
+	if len(w) > 3 && strings.HasSuffix(w, "e") {
+		w = w[:len(w)-1]
+	}
+	return w
+}
+
+// parseQuery splits a query into its terms and its quoted phrases. Words of
+// phrases are also returned as terms.
+func parseQuery(query string) (words []string, phrases [][]string) {
+	seen := make(map[string]bool)
+	add := func(ts []string) {
+		for _, t := range ts {
+			if !seen[t] {
+				seen[t] = true
+				words = append(words, t)
+			}
+		}
+	}
+	for i, part := range strings.Split(query, `"`) {
+		ts := terms(part)
+		// Odd parts are inside quotes. An unterminated quote still counts.
+		if i%2 == 1 && len(ts) > 1 {
+			phrases = append(phrases, ts)
+		}
+		add(ts)
+	}
+	return words, phrases
+}
@@ -0,0 +1,163 @@
+package fulltext
+
+import (
+	"context"
+	"testing"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/notionsync"
+)
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"rotated":  "rotat",
+		"rotates":  "rotat",
+		"rotate":   "rotat",
+		"running":  "run",
+		"called":   "call",
+		"policies": "policy",
+		"keys":     "key",
+		"status":   "status",
+		"need":     "need",
+		"sing":     "sing",
+	}
+	for word, want := range tests {
+		if got := stem(word); got != want {
+			t.Errorf("stem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func ids(hits []Hit) []string {
+	var out []string
+	for _, h := range hits {
+		out = append(out, h.Page.ID)
+	}
+	return out
+}
+
+func TestSearch(t *testing.T) {
+	ix := NewIndex()
+	ix.Add(&notion.PageContent{ID: "keys", Title: "Security", Markdown: "## Credentials\n\nWe rotate the API keys every quarter. See [the runbook](https://www.notion.so/abc)."})
+	ix.Add(&notion.PageContent{ID: "title", Title: "API keys", Markdown: "Placeholder."})
+	ix.Add(&notion.PageContent{ID: "lunch", Title: "Office", Markdown: "The lunch menu is posted weekly.", Properties: map[string]any{
+		"Tags": map[string]any{"type": "multi_select", "multi_select": []any{map[string]any{"name": "Food"}}},
+	}})
+	ctx := context.Background()
+
+	hits, err := ix.Search(ctx, "when are keys rotated", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(hits); len(got) != 2 || got[0] != "keys" {
+		t.Fatalf("expected the body match first, got %v", got)
+	}
+	if want := "We **rotate** the API **keys** every quarter. See [the runbook]."; hits[0].Snippet != want {
+		t.Fatalf("unexpected snippet:\n%s\nwant:\n%s", hits[0].Snippet, want)
+	}
+
+	// A phrase must appear as written.
+	if hits, _ := ix.Search(ctx, `"keys every quarter"`, 0); len(hits) != 1 || hits[0].Page.ID != "keys" {
+		t.Fatalf("unexpected phrase hits: %v", ids(hits))
+	}
+	if hits, _ := ix.Search(ctx, `"quarter keys"`, 0); len(hits) != 0 {
+		t.Fatalf("expected no hits for a reordered phrase, got %v", ids(hits))
+	}
+	// Headings, properties and link targets.
+	if hits, _ := ix.Search(ctx, "credential", 0); len(ids(hits)) != 1 {
+		t.Fatalf("expected a heading match, got %v", ids(hits))
+	}
+	if hits, _ := ix.Search(ctx, "food", 0); len(hits) != 1 || hits[0].Page.ID != "lunch" {
+		t.Fatalf("expected a property match, got %v", ids(hits))
+	}
+	if hits, _ := ix.Search(ctx, "notion", 0); len(hits) != 0 {
+		t.Fatalf("expected link targets not to be indexed, got %v", ids(hits))
+	}
+	// Only "# " lines outside code fences are headings.
+	goals := NewIndex()
+	goals.Add(&notion.PageContent{ID: "goals", Title: "Goals", Markdown: "#1 priority is uptime.\n\n```\n# deploy nightly\n```"})
+	if hits, _ := goals.Search(ctx, "uptime", 0); len(hits) != 1 || hits[0].Snippet != "#1 priority is **uptime**. # deploy nightly" {
+		t.Fatalf("expected a body match, got %+v", hits)
+	}
+	if hits, _ := goals.Search(ctx, "nightly", 0); len(hits) != 1 || hits[0].Snippet != "#1 priority is uptime. # deploy **nightly**" {
+		t.Fatalf("expected a match in code, got %+v", hits)
+	}
+
+	// A zero boost ignores matches in a field.
+	boosted := NewIndex(WithFieldBoost(FieldTitle, 0))
+	boosted.Add(&notion.PageContent{ID: "title", Title: "Lunch"})
+	if hits, _ := boosted.Search(ctx, "lunch", 0); len(hits) != 0 {
+		t.Fatalf("expected no hits with the title boost off, got %+v", hits)
+	}
+
+	if !ix.Remove("lunch") || ix.Len() != 2 {
+		t.Fatalf("expected the page to be removed")
+	}
+	if hits, _ := ix.Search(ctx, "lunch", 0); len(hits) != 0 {
+		t.Fatalf("expected no hits after removal, got %v", ids(hits))
+	}
+	for term, docs := range ix.postings {
+		if docs["lunch"] != nil {
+			t.Fatalf("removed page still posted under %q", term)
+		}
+	}
+}
+
+func TestSearchBlockID(t *testing.T) {
+	ix := NewIndex()
+	ix.Add(&notion.PageContent{
+		ID:       "p",
+		Title:    "Runbook",
+		Markdown: "## Setup\n\nInstall the agent.\n\n```\ncode\n```\n\nRotate the keys.",
+		SourceMap: notion.SourceMap{
+			{BlockID: "h", StartLine: 1, EndLine: 1},
+			{BlockID: "a", StartLine: 3, EndLine: 3},
+			{BlockID: "c", StartLine: 5, EndLine: 7},
+			{BlockID: "b", StartLine: 9, EndLine: 9},
+		},
+	})
+	hits, err := ix.Search(context.Background(), "keys", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 1 || hits[0].BlockID != "b" {
+		t.Fatalf("expected the match in block b, got %+v", hits)
+	}
+}
+
+func TestSyncStore(t *testing.T) {
+	store := notionsync.NewMemoryStore()
+	put := func(id, edited, md string) {
+		if err := store.Put(&notionsync.Record{ID: id, Kind: notionsync.KindPage, Title: id, LastEditedTime: edited, Markdown: md}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	put("a", "2024-01-01T00:00:00.000Z", "alpha")
+	put("b", "2024-01-01T00:00:00.000Z", "beta")
+	if err := store.Put(&notionsync.Record{ID: "db", Kind: notionsync.KindDatabase, Title: "db"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ix := NewIndex()
+	res, err := ix.SyncStore(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *res != (SyncResult{Added: 2}) {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	put("a", "2024-02-01T00:00:00.000Z", "gamma")
+	if err := store.Delete("b"); err != nil {
+		t.Fatal(err)
+	}
+	if res, err = ix.SyncStore(store); err != nil {
+		t.Fatal(err)
+	}
+	if *res != (SyncResult{Added: 1, Removed: 1}) {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if hits, _ := ix.Search(context.Background(), "gamma", 0); len(hits) != 1 || hits[0].Page.LastEditedTime != "2024-02-01T00:00:00.000Z" {
+		t.Fatalf("expected the edited page to be reindexed, got %+v", hits)
+	}
+}
@@ -0,0 +1,394 @@
+// Package fulltext is a local full-text index over converted Notion pages.
+//
+// Notion's search API matches mostly on titles, so questions about what a
+// page says often find the wrong page. An Index ranks pages by BM25 over
+// their title, headings, properties and body, with stemming, quoted phrase
+// queries and per-field boosts, and returns highlighted snippets. Built from
+// a notionsync store it works offline and is refreshed from each page's
+// last_edited_time.
+package fulltext
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/internal/mdsplit"
+	"github.com/openai/notion-go-agents/notionsync"
+)
+
+// Field is a part of a page that is indexed separately.
+type Field int
+
+const (
+	FieldTitle Field = iota
+	FieldHeadings
+	FieldProperties
+	FieldBody
+	numFields
+)
+
+// Hit is a page found by a search.
+type Hit struct {
+	Page  *notion.PageContent
+	Score float64
+	// Snippet is a short excerpt of the page body around the best match,
+	// with matched words in bold.
+	Snippet string
+	// BlockID is the block the snippet's best match was rendered from, when
+	// the page was converted with notion.WithSourceMap.
+	BlockID string
+}
+
+// Searcher finds pages matching a query, best first.
+type Searcher interface {
+	Search(ctx context.Context, query string, limit int) ([]Hit, error)
+}
+
+// Option configures an Index.
+type Option func(*Index)
+
+// WithFieldBoost sets the weight of matches in a field. The defaults are 3
+// for the title, 2 for headings, 1.5 for properties and 1 for the body.
+func WithFieldBoost(f Field, boost float64) Option {
+	return func(ix *Index) {
+		if f >= 0 && f < numFields && boost >= 0 {
+			ix.boosts[f] = boost
+		}
+	}
+}
+
+// Index is an in-memory inverted index of pages. It is safe for concurrent
+// use and implements Searcher.
+type Index struct {
+	boosts [numFields]float64
+
+	mu       sync.RWMutex
+	docs     map[string]*document
+	postings map[string]map[string]*posting
+	// totals holds the summed length of each field over all documents.
+	totals [numFields]int
+}
+
+type document struct {
+	page    *notion.PageContent
+	body    string
+	bodyTok []token
+	// lines holds the Markdown line number, from 1, of each line of body.
+	lines   []int
+	lengths [numFields]int
+	// terms holds the distinct terms of the document, so removing it only
+	// touches their postings.
+	terms []string
+}
+
+// posting holds the positions of a term in each field of a document.
+type posting struct {
+	positions [numFields][]int
+}
+
+// NewIndex returns an empty index.
+func NewIndex(opts ...Option) *Index {
+	ix := &Index{
+		boosts:   [numFields]float64{3, 2, 1.5, 1},
+		docs:     make(map[string]*document),
+		postings: make(map[string]map[string]*posting),
+	}
+	for _, opt := range opts {
+		opt(ix)
+	}
+	return ix
+}
+
+// Len returns the number of indexed pages.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Version returns the last_edited_time of the indexed copy of a page, which
+// callers compare with the page's current one to decide whether to re-add it.
+func (ix *Index) Version(pageID string) (string, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	d, ok := ix.docs[notion.NormalizeID(pageID)]
+	if !ok {
+		return "", false
+	}
+	return d.page.LastEditedTime, true
+}
+
+// markdownLink matches the target of a Markdown link or image, which is
+// dropped so URLs do not pollute the index.
+var markdownLink = regexp.MustCompile(`\]\([^)\s]*\)`)
+
+// Add indexes a page, replacing an earlier copy with the same ID. Lines of
+// its Markdown starting with "#" outside code blocks are indexed as headings.
+func (ix *Index) Add(pc *notion.PageContent) {
+	id := notion.NormalizeID(pc.ID)
+	var headings, body []string
+	var lines []int
+	for _, part := range mdsplit.Split(pc.Markdown) {
+		if part.Level > 0 {
+			headings = append(headings, part.Title)
+			continue
+		}
+		// Blocks stay separated by a blank line, as on the page.
+		if len(body) > 0 {
+			body = append(body, "")
+			lines = append(lines, part.Line-1)
+		}
+		for i, line := range part.Lines {
+			if strings.HasPrefix(strings.TrimSpace(line), "```") {
+				continue
+			}
+			body = append(body, line)
+			lines = append(lines, part.Line+i)
+		}
+	}
+	var props []string
+	for _, v := range pc.Properties {
+		prop, _ := v.(map[string]any)
+		if t, _ := prop["type"].(string); t == "title" {
+			continue
+		}
+		if text := notion.PropertyText(prop); text != "" {
+			props = append(props, text)
+		}
+	}
+	sort.Strings(props)
+
+	d := &document{page: pc, body: markdownLink.ReplaceAllString(strings.Join(body, "\n"), "]"), lines: lines}
+	d.bodyTok = tokenize(d.body)
+	fields := [numFields][]string{
+		FieldTitle:      terms(pc.Title),
+		FieldHeadings:   terms(strings.Join(headings, "\n")),
+		FieldProperties: terms(strings.Join(props, "\n")),
+	}
+	fields[FieldBody] = make([]string, len(d.bodyTok))
+	for i, t := range d.bodyTok {
+		fields[FieldBody][i] = t.term
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(id)
+	ix.docs[id] = d
+	for f, ts := range fields {
+		d.lengths[f] = len(ts)
+		ix.totals[f] += len(ts)
+		for pos, term := range ts {
+			docs := ix.postings[term]
+			if docs == nil {
+				docs = make(map[string]*posting)
+				ix.postings[term] = docs
+			}
+			p := docs[id]
+			if p == nil {
+				p = &posting{}
+				docs[id] = p
+				d.terms = append(d.terms, term)
+			}
+			p.positions[f] = append(p.positions[f], pos)
+		}
+	}
+}
+
+// Remove drops a page from the index and reports whether it was indexed.
+func (ix *Index) Remove(pageID string) bool {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return ix.remove(notion.NormalizeID(pageID))
+}
+
+func (ix *Index) remove(id string) bool {
+	d, ok := ix.docs[id]
+	if !ok {
+		return false
+	}
+	delete(ix.docs, id)
+	for f := range d.lengths {
+		ix.totals[f] -= d.lengths[f]
+	}
+	for _, term := range d.terms {
+		docs := ix.postings[term]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+	return true
+}
+
+// SyncResult counts the pages changed by SyncStore.
+type SyncResult struct {
+	Added   int
+	Removed int
+}
+
+// SyncStore brings the index up to date with the pages of a notionsync
+// store: pages whose last_edited_time differs from the indexed copy are
+// re-added and pages no longer in the store are removed.
+func (ix *Index) SyncStore(store notionsync.Store) (*SyncResult, error) {
+	recs, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list records: %w", err)
+	}
+	res := &SyncResult{}
+	keep := make(map[string]bool)
+	for _, rec := range recs {
+		if rec.Kind != notionsync.KindPage {
+			continue
+		}
+		keep[notion.NormalizeID(rec.ID)] = true
+		if v, ok := ix.Version(rec.ID); ok && v == rec.LastEditedTime {
+			continue
+		}
+		pc := &notion.PageContent{
+			ID:             rec.ID,
+			Title:          rec.Title,
+			Markdown:       rec.Markdown,
+			URL:            rec.URL,
+			LastEditedTime: rec.LastEditedTime,
+		}
+		if rec.Page != nil {
+			pc.Properties = rec.Page.Properties
+			pc.Parent = rec.Page.Parent
+		}
+		ix.Add(pc)
+		res.Added++
+	}
+	ix.mu.RLock()
+	var stale []string
+	for id := range ix.docs {
+		if !keep[id] {
+			stale = append(stale, id)
+		}
+	}
+	ix.mu.RUnlock()
+	for _, id := range stale {
+		if ix.Remove(id) {
+			res.Removed++
+		}
+	}
+	return res, nil
+}
+
+// BM25 parameters.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Search returns up to limit pages matching any word of query, ranked by
+// BM25 with field boosts. Quoted phrases must appear as written, within one
+// field, for a page to match, and pages that only match in fields with a zero
+// boost are left out. A limit of 0 or less returns every match.
+func (ix *Index) Search(ctx context.Context, query string, limit int) ([]Hit, error) {
+	words, phrases := parseQuery(query)
+	if len(words) == 0 {
+		return nil, nil
+	}
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	n := float64(len(ix.docs))
+	var avg [numFields]float64
+	for f := range avg {
+		if n > 0 {
+			avg[f] = float64(ix.totals[f]) / n
+		}
+	}
+	scores := make(map[string]float64)
+	for _, w := range words {
+		docs := ix.postings[w]
+		if len(docs) == 0 {
+			continue
+		}
+		df := float64(len(docs))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, p := range docs {
+			d := ix.docs[id]
+			// BM25F: combine boosted, length-normalized term frequencies
+			// across fields before saturating.
+			tf := 0.0
+			for f := Field(0); f < numFields; f++ {
+				if len(p.positions[f]) == 0 || avg[f] == 0 {
+					continue
+				}
+				norm := 1 - b + b*float64(d.lengths[f])/avg[f]
+				tf += ix.boosts[f] * float64(len(p.positions[f])) / norm
+			}
+			scores[id] += idf * tf * (k1 + 1) / (k1 + tf)
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		if score <= 0 || !ix.hasPhrases(id, phrases) {
+			continue
+		}
+		d := ix.docs[id]
+		hit := Hit{Page: d.page, Score: score}
+		var offset int
+		hit.Snippet, offset = snippet(d.body, d.bodyTok, words)
+		if sm := d.page.SourceMap; len(sm) > 0 && len(d.lines) > 0 {
+			hit.BlockID = sm.BlockAtLine(d.lines[strings.Count(d.body[:offset], "\n")])
+		}
+		hits = append(hits, hit)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Page.ID < hits[j].Page.ID
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// hasPhrases reports whether every phrase occurs in some field of a
+// document.
+func (ix *Index) hasPhrases(id string, phrases [][]string) bool {
+	for _, phrase := range phrases {
+		found := false
+		for f := Field(0); f < numFields && !found; f++ {
+			found = ix.hasPhrase(id, f, phrase)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (ix *Index) hasPhrase(id string, f Field, phrase []string) bool {
+	first := ix.postings[phrase[0]][id]
+	if first == nil {
+		return false
+	}
+next:
+	for _, start := range first.positions[f] {
+		for i, w := range phrase[1:] {
+			p := ix.postings[w][id]
+			if p == nil || !containsInt(p.positions[f], start+i+1) {
+				continue next
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func containsInt(sorted []int, v int) bool {
+	i := sort.SearchInts(sorted, v)
+	return i < len(sorted) && sorted[i] == v
+}
@@ -0,0 +1,74 @@
+package fulltext
+
+import "strings"
+
+// snippetWords is the length of a snippet in words.
+const snippetWords = 24
+
+// snippet returns the window of text with the most distinct query words,
+// with matched words in bold, or the start of text when nothing matches. It
+// also returns the byte offset of the window's first match.
+func snippet(text string, toks []token, words []string) (string, int) {
+	if len(toks) == 0 {
+		return "", 0
+	}
+	want := make(map[string]bool, len(words))
+	for _, w := range words {
+		want[w] = true
+	}
+	best, bestDistinct, bestCount := 0, -1, -1
+	for start := 0; start < len(toks); start++ {
+		if start > 0 && !want[toks[start].term] {
+			continue
+		}
+		distinct := make(map[string]bool)
+		count := 0
+		for _, t := range toks[start:min(start+snippetWords, len(toks))] {
+			if want[t.term] {
+				distinct[t.term] = true
+				count++
+			}
+		}
+		if len(distinct) > bestDistinct || (len(distinct) == bestDistinct && count > bestCount) {
+			best, bestDistinct, bestCount = start, len(distinct), count
+		}
+	}
+	// Start a few words before the first match for context.
+	start := max(0, best-3)
+	if bestCount == 0 {
+		start = 0
+	}
+	end := min(start+snippetWords, len(toks))
+
+	offset := toks[start].start
+	for _, t := range toks[best:end] {
+		if want[t.term] {
+			offset = t.start
+			break
+		}
+	}
+
+	var out strings.Builder
+	if start > 0 {
+		out.WriteString("…")
+	}
+	pos := toks[start].start
+	if start == 0 {
+		pos = 0
+	}
+	for _, t := range toks[start:end] {
+		out.WriteString(text[pos:t.start])
+		if want[t.term] {
+			out.WriteString("**" + text[t.start:t.end] + "**")
+		} else {
+			out.WriteString(text[t.start:t.end])
+		}
+		pos = t.end
+	}
+	if end < len(toks) {
+		out.WriteString("…")
+	} else {
+		out.WriteString(text[pos:])
+	}
+	return strings.Join(strings.Fields(out.String()), " "), offset
+}
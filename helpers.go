@@ -3,26 +3,54 @@ package notion
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 )
 
 // PageContent contains basic page information returned by helpers.
 type PageContent struct {
-	ID         string
-	Title      string
-	Markdown   string
-	URL        string
-	Properties map[string]any
+	ID             string
+	Title          string
+	Markdown       string
+	URL            string
+	Properties     map[string]any
+	LastEditedTime string
+	// Parent is the page's raw parent object, such as
+	// {"type": "page_id", "page_id": "..."}.
+	Parent map[string]any
+	// SourceMap maps Markdown to the blocks it was rendered from. It is only
+	// set when the page was converted with WithSourceMap.
+	SourceMap SourceMap
 }
 
-// GetPageContent retrieves a Notion page by ID and converts it to Markdown.
-func GetPageContent(ctx context.Context, client *Client, pageID string) (*PageContent, error) {
+// ErrStopWalk can be returned by the callback of WalkWorkspace or
+// WalkNotionDatabase to end the walk early without an error.
+var ErrStopWalk = errors.New("stop walk")
+
+// GetPageContent retrieves a Notion page by ID or URL and converts it to
+// Markdown.
+// Converter options can be passed to control how the page body is rendered.
+func GetPageContent(ctx context.Context, client *Client, pageID string, opts ...ConverterOption) (*PageContent, error) {
 	pg, err := client.GetPage(ctx, pageID)
 	if err != nil {
 		return nil, err
 	}
-	conv := NewNotionMarkdownConverter(client)
-	md, err := conv.ConvertPageToMarkdown(ctx, pageID)
+	return ConvertPage(ctx, client, pg, opts...)
+}
+
+// ConvertPage converts a page object that was already fetched, for example by
+// WalkWorkspace, into PageContent. The page's last_edited_time validates any
+// content cached for the client.
+func ConvertPage(ctx context.Context, client *Client, pg *NotionPage, opts ...ConverterOption) (*PageContent, error) {
+	conv := NewNotionMarkdownConverter(client, opts...)
+	var md string
+	var sm SourceMap
+	var err error
+	if conv.sourceMap {
+		md, sm, err = conv.convertPageMapped(ctx, pg)
+	} else {
+		md, err = conv.convertPage(ctx, pg.ID, pg)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert page to markdown: %w", err)
 	}
@@ -35,69 +63,124 @@ func GetPageContent(ctx context.Context, client *Client, pageID string) (*PageCo
 	}
 
 	return &PageContent{
-		ID:         pg.ID,
-		Title:      title,
-		Markdown:   md,
-		URL:        url,
-		Properties: props,
+		ID:             pg.ID,
+		Title:          title,
+		Markdown:       md,
+		URL:            url,
+		Properties:     props,
+		LastEditedTime: pg.LastEditedTime,
+		Parent:         pg.Parent,
+		SourceMap:      sm,
 	}, nil
 }
 
-// SearchWorkspace searches the workspace and returns up to limit page results.
+// SearchWorkspace searches the workspace and returns up to limit page results,
+// paging through the search results until limit pages were converted. A limit
+// of 0 converts the pages in the first page of search results only; use
+// WalkWorkspace to go through every result. Each result costs a conversion
+// of the page, which fetches all of its blocks.
 func SearchWorkspace(ctx context.Context, client *Client, req NotionSearchRequest, limit int) ([]PageContent, error) {
-	ids, err := client.SearchPages(ctx, req, limit)
-	if err != nil {
-		return nil, err
-	}
-	out := make([]PageContent, 0, len(ids))
-	for _, id := range ids {
-		pc, err := GetPageContent(ctx, client, id)
+	return collectPages(ctx, client, limit, func(fn func(*NotionPage) error) error {
+		if limit > 0 {
+			return WalkWorkspace(ctx, client, req, fn)
+		}
+		resp, err := client.Search(ctx, req)
 		if err != nil {
-			continue
+			return err
+		}
+		_, err = walkResults(resp.Results, fn)
+		return err
+	})
+}
+
+// SearchNotionDatabase queries a database (paginated) and returns up to limit page results.
+func SearchNotionDatabase(ctx context.Context, client *Client, databaseID string, req NotionDatabaseQueryRequest, limit int) ([]PageContent, error) {
+	return collectPages(ctx, client, limit, func(fn func(*NotionPage) error) error {
+		return WalkNotionDatabase(ctx, client, databaseID, req, fn)
+	})
+}
+
+// collectPages converts the pages produced by walk until limit pages were
+// converted, skipping pages that fail to convert.
+func collectPages(ctx context.Context, client *Client, limit int, walk func(func(*NotionPage) error) error) ([]PageContent, error) {
+	out := []PageContent{}
+	err := walk(func(pg *NotionPage) error {
+		pc, err := ConvertPage(ctx, client, pg)
+		if err != nil {
+			return nil
 		}
 		out = append(out, *pc)
 		if limit > 0 && len(out) >= limit {
-			break
+			return ErrStopWalk
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return out, nil
 }
 
-// SearchNotionDatabase queries a database (paginated) and returns up to limit page results.
-func SearchNotionDatabase(ctx context.Context, client *Client, databaseID string, req NotionDatabaseQueryRequest, limit int) ([]PageContent, error) {
-	// Use large page size and paginate internally; enforce overall limit manually.
-	req.PageSize = 100
+// WalkWorkspace runs a workspace search and calls fn with each page result as
+// the results are paged in, so callers can process large result sets without
+// holding them. Databases in the results are skipped. Convert pages with
+// ConvertPage. If fn returns ErrStopWalk the walk ends without error; any
+// other error ends it and is returned.
+func WalkWorkspace(ctx context.Context, client *Client, req NotionSearchRequest, fn func(*NotionPage) error) error {
+	if req.PageSize == 0 {
+		req.PageSize = 100
+	}
+	for {
+		resp, err := client.Search(ctx, req)
+		if err != nil {
+			return err
+		}
+		if stop, err := walkResults(resp.Results, fn); stop || err != nil {
+			return err
+		}
+		if !resp.HasMore || resp.NextCursor == "" {
+			return nil
+		}
+		req.StartCursor = resp.NextCursor
+	}
+}
 
-	var out []PageContent
-	cursor := ""
+// WalkNotionDatabase queries a database and calls fn with each row as the
+// results are paged in, like WalkWorkspace.
+func WalkNotionDatabase(ctx context.Context, client *Client, databaseID string, req NotionDatabaseQueryRequest, fn func(*NotionPage) error) error {
+	// Use large page size and paginate internally.
+	req.PageSize = 100
 	for {
-		req.StartCursor = cursor
 		resp, err := client.QueryDatabase(ctx, databaseID, req)
 		if err != nil {
-			return nil, err
+			return err
 		}
-
-		for _, raw := range resp.Results {
-			var pg NotionPage
-			if err := json.Unmarshal(raw, &pg); err != nil {
-				continue
-			}
-			pc, err := GetPageContent(ctx, client, pg.ID)
-			if err != nil {
-				continue
-			}
-			out = append(out, *pc)
-			if limit > 0 && len(out) >= limit {
-				return out[:limit], nil
-			}
+		if stop, err := walkResults(resp.Results, fn); stop || err != nil {
+			return err
 		}
-
 		if !resp.HasMore || resp.NextCursor == "" {
-			break
+			return nil
 		}
-		cursor = resp.NextCursor
+		req.StartCursor = resp.NextCursor
 	}
-	return out, nil
+}
+
+// walkResults calls fn for each page in a page of raw results and reports
+// whether the walk should stop.
+func walkResults(results []json.RawMessage, fn func(*NotionPage) error) (bool, error) {
+	for _, raw := range results {
+		var pg NotionPage
+		if err := json.Unmarshal(raw, &pg); err != nil || pg.Object != "page" {
+			continue
+		}
+		if err := fn(&pg); err != nil {
+			if errors.Is(err, ErrStopWalk) {
+				return true, nil
+			}
+			return true, err
+		}
+	}
+	return false, nil
 }
 
 // FindPageByQuery is a small convenience wrapper that returns the first matching page.
@@ -0,0 +1,41 @@
+package notion
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openai/notion-go-agents/internal/notiontest"
+)
+
+func TestSearchWorkspace(t *testing.T) {
+	srv := notiontest.New(t)
+	for _, id := range []string{"a", "b", "c"} {
+		srv.AddPage(notiontest.Page(id, "Runbook "+id))
+	}
+	client := newTestClient(srv)
+	ctx := context.Background()
+	req := NotionSearchRequest{Query: "runbook", PageSize: 2}
+
+	// Without a limit only the first page of search results is converted.
+	pages, err := SearchWorkspace(ctx, client, req, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 2 || srv.Requests("POST /v1/search") != 1 {
+		t.Fatalf("got %d pages from %d searches", len(pages), srv.Requests("POST /v1/search"))
+	}
+
+	// A limit pages through the results until it is reached.
+	pages, err = SearchWorkspace(ctx, client, req, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 3 || pages[2].Title != "Runbook c" {
+		t.Fatalf("unexpected pages %+v", pages)
+	}
+
+	pages, err = SearchWorkspace(ctx, client, NotionSearchRequest{Query: "missing"}, 0)
+	if err != nil || pages == nil || len(pages) != 0 {
+		t.Fatalf("expected an empty result, got %v, %v", pages, err)
+	}
+}
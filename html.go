@@ -0,0 +1,358 @@
+package notion
+
+import (
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// RenderHTML renders a block tree fetched with GetBlockTree as an HTML
+// fragment. Lists, to-dos, toggles, tables, columns and child databases map
+// to their natural HTML elements; rich text keeps its annotations and links.
+// Converter options that only concern Markdown syntax, such as color and
+// toggle modes, do not apply.
+func (c *NotionMarkdownConverter) RenderHTML(nodes []BlockNode) string {
+	var b strings.Builder
+	c.writeHTMLBlocks(&b, nodes, 0)
+	return b.String()
+}
+
+// htmlList returns the list element a block type renders in, with its
+// attributes, or "" for blocks that are not list items.
+func htmlList(blockType string) string {
+	switch blockType {
+	case "bulleted_list_item":
+		return "ul"
+	case "to_do":
+		return `ul class="to-do"`
+	case "numbered_list_item":
+		return "ol"
+	}
+	return ""
+}
+
+func (c *NotionMarkdownConverter) writeHTMLBlocks(b *strings.Builder, nodes []BlockNode, shift int) {
+	open := ""
+	for _, node := range nodes {
+		blockType, _ := node.Block["type"].(string)
+		list := htmlList(blockType)
+		if list != open {
+			if open != "" {
+				b.WriteString("</" + strings.Fields(open)[0] + ">\n")
+			}
+			if list != "" {
+				b.WriteString("<" + list + ">\n")
+			}
+			open = list
+		}
+		c.writeHTMLBlock(b, node, shift)
+	}
+	if open != "" {
+		b.WriteString("</" + strings.Fields(open)[0] + ">\n")
+	}
+}
+
+func (c *NotionMarkdownConverter) writeHTMLBlock(b *strings.Builder, node BlockNode, shift int) {
+	block := node.Block
+	blockType, _ := block["type"].(string)
+	data, _ := block[blockType].(map[string]any)
+	text := c.richTextHTML(richTextOf(data))
+	children := func() {
+		c.writeHTMLBlocks(b, node.Children, shift)
+	}
+	switch blockType {
+	case "paragraph":
+		if text != "" {
+			b.WriteString("<p>" + text + "</p>\n")
+		}
+		children()
+	case "heading_1", "heading_2", "heading_3":
+		if text != "" {
+			tag := "h" + strconv.Itoa(min(headingLevel(blockType)+shift, 6))
+			b.WriteString("<" + tag + ">" + text + "</" + tag + ">\n")
+		}
+		if len(node.Children) > 0 {
+			b.WriteString("<div>\n")
+			children()
+			b.WriteString("</div>\n")
+		}
+	case "bulleted_list_item", "numbered_list_item", "to_do":
+		b.WriteString("<li>")
+		if blockType == "to_do" {
+			b.WriteString(`<input type="checkbox" disabled`)
+			if checked, _ := data["checked"].(bool); checked {
+				b.WriteString(" checked")
+			}
+			b.WriteString("> ")
+		}
+		b.WriteString(text)
+		if len(node.Children) > 0 {
+			b.WriteString("\n")
+			children()
+		}
+		b.WriteString("</li>\n")
+	case "quote":
+		b.WriteString("<blockquote>\n")
+		if text != "" {
+			b.WriteString("<p>" + text + "</p>\n")
+		}
+		children()
+		b.WriteString("</blockquote>\n")
+	case "callout":
+		b.WriteString(`<aside class="callout">` + "\n")
+		if icon, _ := data["icon"].(map[string]any); icon["type"] == "emoji" {
+			if emoji, _ := icon["emoji"].(string); emoji != "" {
+				text = html.EscapeString(emoji) + " " + text
+			}
+		}
+		if text != "" {
+			b.WriteString("<p>" + text + "</p>\n")
+		}
+		children()
+		b.WriteString("</aside>\n")
+	case "toggle":
+		b.WriteString("<details>\n<summary>" + text + "</summary>\n")
+		children()
+		b.WriteString("</details>\n")
+	case "code":
+		rt, _ := data["rich_text"].([]any)
+		code := strings.Trim(rawRichText(rt), "\n")
+		if strings.TrimSpace(code) == "" {
+			return
+		}
+		b.WriteString("<pre><code")
+		if language, _ := data["language"].(string); language != "" {
+			b.WriteString(` class="language-` + html.EscapeString(language) + `"`)
+		}
+		b.WriteString(">" + html.EscapeString(code) + "</code></pre>\n")
+	case "divider":
+		b.WriteString("<hr>\n")
+	case "equation":
+		if expression, _ := data["expression"].(string); expression != "" {
+			b.WriteString(`<div class="equation">\[` + html.EscapeString(expression) + `\]</div>` + "\n")
+		}
+	case "bookmark", "embed", "link_preview":
+		if url, _ := data["url"].(string); url != "" {
+			if !safeURL(url) {
+				b.WriteString("<p>" + html.EscapeString(url) + "</p>\n")
+				return
+			}
+			b.WriteString(`<p><a href="` + html.EscapeString(url) + `">` + html.EscapeString(url) + "</a></p>\n")
+		}
+	case "link_to_page":
+		linkType, _ := data["type"].(string)
+		if id, _ := data[linkType].(string); id != "" {
+			b.WriteString(`<p><a href="` + html.EscapeString(notionURL(id)) + `">` + html.EscapeString(c.pageTitle(id, "")) + "</a></p>\n")
+		}
+	case "image":
+		url, _ := fileObjectURL(data)
+		if node.assetURL != "" {
+			url = node.assetURL
+		}
+		if url == "" {
+			return
+		}
+		caption, _ := data["caption"].([]any)
+		if !safeURL(url) {
+			b.WriteString("<p>Image: " + html.EscapeString(url) + "</p>\n")
+			return
+		}
+		b.WriteString(`<figure><img src="` + html.EscapeString(url) + `" alt="` + html.EscapeString(PlainText(caption)) + `">`)
+		if captionHTML := c.richTextHTML(caption); captionHTML != "" {
+			b.WriteString("<figcaption>" + captionHTML + "</figcaption>")
+		}
+		b.WriteString("</figure>\n")
+	case "video", "audio", "pdf", "file":
+		url, _ := fileObjectURL(data)
+		if node.assetURL != "" {
+			url = node.assetURL
+		}
+		if url == "" {
+			return
+		}
+		name, _ := data["name"].(string)
+		if name == "" {
+			name = fileNameFromURL(url)
+		}
+		if !safeURL(url) {
+			b.WriteString("<p>" + mediaLabels[blockType] + ": " + html.EscapeString(name) + "</p>\n")
+			return
+		}
+		b.WriteString(`<p><a href="` + html.EscapeString(url) + `">` + mediaLabels[blockType] + ": " + html.EscapeString(name) + "</a></p>\n")
+	case "child_page":
+		title, _ := data["title"].(string)
+		if title == "" {
+			title = "Untitled"
+		}
+		if node.pageDepth > 0 {
+			tag := "h" + strconv.Itoa(min(shift+2, 6))
+			b.WriteString("<section>\n<" + tag + ">" + html.EscapeString(title) + "</" + tag + ">\n")
+			c.writeHTMLBlocks(b, node.Children, shift+2)
+			b.WriteString("</section>\n")
+			return
+		}
+		id, _ := block["id"].(string)
+		b.WriteString(`<p><a href="` + html.EscapeString(notionURL(id)) + `">` + html.EscapeString(title) + "</a></p>\n")
+	case "child_database":
+		if title, _ := data["title"].(string); title != "" {
+			tag := "h" + strconv.Itoa(min(shift+2, 6))
+			b.WriteString("<" + tag + ">" + html.EscapeString(title) + "</" + tag + ">\n")
+		}
+		if node.database != nil {
+			writeDatabaseHTML(b, node.database)
+		}
+	case "table":
+		c.writeTableHTML(b, node)
+	case "column_list":
+		b.WriteString(`<div class="columns">` + "\n")
+		for _, column := range node.Children {
+			b.WriteString(`<div class="column">` + "\n")
+			c.writeHTMLBlocks(b, column.Children, shift)
+			b.WriteString("</div>\n")
+		}
+		b.WriteString("</div>\n")
+	case "column", "synced_block":
+		children()
+	default:
+		if text != "" {
+			b.WriteString("<p>" + text + "</p>\n")
+		}
+		children()
+	}
+}
+
+func (c *NotionMarkdownConverter) writeTableHTML(b *strings.Builder, node BlockNode) {
+	meta, _ := node.Block["table"].(map[string]any)
+	columnHeader, _ := meta["has_column_header"].(bool)
+	rowHeader, _ := meta["has_row_header"].(bool)
+	b.WriteString("<table>\n")
+	first := true
+	for _, child := range node.Children {
+		row, ok := child.Block["table_row"].(map[string]any)
+		if !ok {
+			continue
+		}
+		cells, _ := row["cells"].([]any)
+		header := first && columnHeader
+		if header {
+			b.WriteString("<thead>\n")
+		}
+		b.WriteString("<tr>")
+		for i, cell := range cells {
+			rt, _ := cell.([]any)
+			tag := "td"
+			if header || (i == 0 && rowHeader) {
+				tag = "th"
+			}
+			b.WriteString("<" + tag + ">" + c.richTextHTML(rt) + "</" + tag + ">")
+		}
+		b.WriteString("</tr>\n")
+		if header {
+			b.WriteString("</thead>\n")
+		}
+		first = false
+	}
+	b.WriteString("</table>\n")
+}
+
+func writeDatabaseHTML(b *strings.Builder, table *databaseTable) {
+	switch {
+	case table.linked:
+		b.WriteString("<p>Linked database view; its source database is not available through the Notion API.</p>\n")
+		return
+	case table.err != nil && len(table.rows) == 0:
+		b.WriteString("<p>Database could not be loaded.</p>\n")
+		return
+	case len(table.columns) == 0 || len(table.rows) == 0:
+		b.WriteString("<p>Empty database.</p>\n")
+		return
+	}
+	b.WriteString("<table>\n<thead>\n<tr>")
+	for _, name := range table.columns {
+		b.WriteString("<th>" + html.EscapeString(name) + "</th>")
+	}
+	b.WriteString("</tr>\n</thead>\n")
+	for _, row := range table.rows {
+		b.WriteString("<tr>")
+		for _, cell := range row {
+			b.WriteString("<td>" + markdownCellHTML(cell) + "</td>")
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</table>\n")
+	switch {
+	case table.err != nil:
+		b.WriteString("<p>Showing the first " + strconv.Itoa(len(table.rows)) + " rows; the rest could not be loaded.</p>\n")
+	case table.truncated:
+		b.WriteString("<p>Showing the first " + strconv.Itoa(len(table.rows)) + " rows; more rows are not shown.</p>\n")
+	}
+}
+
+// markdownCellHTML turns a table cell escaped for Markdown into HTML,
+// keeping its line breaks.
+func markdownCellHTML(cell string) string {
+	return strings.ReplaceAll(html.EscapeString(markdownCellText(cell)), "\n", "<br>")
+}
+
+// safeURL reports whether u may be used as a link or image source: an http,
+// https or mailto URL, or a relative one. Other schemes, such as
+// javascript:, would run in the reader's browser.
+func safeURL(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// richTextOf returns the rich text array of a block's data.
+func richTextOf(data map[string]any) []any {
+	if rt, ok := data["rich_text"].([]any); ok {
+		return rt
+	}
+	rt, _ := data["text"].([]any)
+	return rt
+}
+
+// richTextHTML renders a rich text array as inline HTML.
+func (c *NotionMarkdownConverter) richTextHTML(richText []any) string {
+	var b strings.Builder
+	for _, item := range richText {
+		itemMap, _ := item.(map[string]any)
+		itemType, _ := itemMap["type"].(string)
+		if itemType == "equation" {
+			equation, _ := itemMap["equation"].(map[string]any)
+			if expr, _ := equation["expression"].(string); expr != "" {
+				b.WriteString(`<span class="equation">\(` + html.EscapeString(expr) + `\)</span>`)
+			}
+			continue
+		}
+		text, _ := itemMap["plain_text"].(string)
+		href, _ := itemMap["href"].(string)
+		if itemType == "mention" {
+			text, href = c.mentionToText(itemMap, text, href)
+		}
+		if text == "" {
+			continue
+		}
+		s := strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+		annotations, _ := itemMap["annotations"].(map[string]any)
+		for _, a := range [...]struct{ key, tag string }{
+			{"code", "code"}, {"bold", "strong"}, {"italic", "em"}, {"strikethrough", "s"}, {"underline", "u"},
+		} {
+			if on, _ := annotations[a.key].(bool); on {
+				s = "<" + a.tag + ">" + s + "</" + a.tag + ">"
+			}
+		}
+		if href != "" && safeURL(href) {
+			s = `<a href="` + html.EscapeString(href) + `">` + s + "</a>"
+		}
+		b.WriteString(s)
+	}
+	return strings.TrimSpace(b.String())
+}
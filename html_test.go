@@ -0,0 +1,82 @@
+package notion
+
+import (
+	"testing"
+
+	"github.com/openai/notion-go-agents/internal/notiontest"
+)
+
+// renderFixture is a small block tree exercising lists, nesting, rich text
+// and tables, shared by the HTML and text renderer tests.
+func renderFixture() []BlockNode {
+	bold := notiontest.TextItem("bold")
+	bold["annotations"] = map[string]any{"bold": true}
+	link := notiontest.TextItem("site")
+	link["href"] = "https://example.com/?a=1&b=2"
+	return []BlockNode{
+		{Block: notiontest.TextBlock("h", "heading_1", "Title <1>")},
+		{Block: notiontest.Block("p", "paragraph", map[string]any{"rich_text": []any{notiontest.TextItem("Some "), bold, notiontest.TextItem(" and "), link}})},
+		{Block: notiontest.TextBlock("b1", "bulleted_list_item", "one"), Children: []BlockNode{
+			{Block: notiontest.TextBlock("b2", "bulleted_list_item", "nested")},
+		}},
+		{Block: notiontest.TextBlock("n1", "numbered_list_item", "first")},
+		{Block: notiontest.TextBlock("n2", "numbered_list_item", "second")},
+		{Block: notiontest.Block("t", "to_do", map[string]any{"rich_text": notiontest.Text("done"), "checked": true})},
+		{Block: notiontest.Block("c", "code", map[string]any{"rich_text": notiontest.Text("a < b"), "language": "go"})},
+		{Block: notiontest.Block("tbl", "table", map[string]any{"has_column_header": true}), Children: []BlockNode{
+			{Block: notiontest.Block("r1", "table_row", map[string]any{"cells": []any{notiontest.Text("K"), notiontest.Text("V")}})},
+			{Block: notiontest.Block("r2", "table_row", map[string]any{"cells": []any{notiontest.Text("a"), notiontest.Text("1")}})},
+		}},
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	got := NewNotionMarkdownConverter(nil).RenderHTML(renderFixture())
+	want := `<h1>Title &lt;1&gt;</h1>
+<p>Some <strong>bold</strong> and <a href="https://example.com/?a=1&amp;b=2">site</a></p>
+<ul>
+<li>one
+<ul>
+<li>nested</li>
+</ul>
+</li>
+</ul>
+<ol>
+<li>first</li>
+<li>second</li>
+</ol>
+<ul class="to-do">
+<li><input type="checkbox" disabled checked> done</li>
+</ul>
+<pre><code class="language-go">a &lt; b</code></pre>
+<table>
+<thead>
+<tr><th>K</th><th>V</th></tr>
+</thead>
+<tr><td>a</td><td>1</td></tr>
+</table>
+`
+	if got != want {
+		t.Fatalf("unexpected HTML:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderHTMLUnsafeURLs(t *testing.T) {
+	link := notiontest.TextItem("click")
+	link["href"] = "javascript:alert(1)"
+	mail := notiontest.TextItem("mail")
+	mail["href"] = "mailto:ops@example.com"
+	nodes := []BlockNode{
+		{Block: notiontest.Block("p", "paragraph", map[string]any{"rich_text": []any{link, notiontest.TextItem(" "), mail}})},
+		{Block: notiontest.Block("bm", "bookmark", map[string]any{"url": "JavaScript:alert(1)"})},
+		{Block: notiontest.Block("img", "image", map[string]any{"type": "external", "external": map[string]any{"url": "data:text/html,<script>"}})},
+	}
+	got := NewNotionMarkdownConverter(nil).RenderHTML(nodes)
+	want := `<p>click <a href="mailto:ops@example.com">mail</a></p>
+<p>JavaScript:alert(1)</p>
+<p>Image: data:text/html,&lt;script&gt;</p>
+`
+	if got != want {
+		t.Fatalf("unexpected HTML:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,110 @@
+package notion
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidID is returned, wrapped, by ParseID and ParseBlockID when their
+// input holds no Notion ID.
+var ErrInvalidID = errors.New("invalid Notion ID")
+
+// uuidPattern matches a Notion ID, dashed or undashed, at the end of a string.
+var uuidPattern = regexp.MustCompile(`(?i)([0-9a-f]{8})-?([0-9a-f]{4})-?([0-9a-f]{4})-?([0-9a-f]{4})-?([0-9a-f]{12})$`)
+
+// ParseID extracts the page or database ID from a Notion ID or URL and
+// returns it in the canonical dashed, lowercase form. It accepts dashed and
+// undashed IDs and the URLs Notion shares pages and databases with:
+//
+//	1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6
+//	https://www.notion.so/Team-Roadmap-1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6?pvs=4
+//	https://www.notion.so/acme/1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6?v=...
+//	https://acme.notion.site/Roadmap-1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6
+//	https://docs.acme.com/Roadmap-1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6
+//	notion://www.notion.so/Roadmap-1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6
+//
+// Any host is accepted, so public pages on custom domains work. When a URL
+// shows a page opened on top of another (the "p" query parameter), that
+// page's ID is returned. Block anchors ("#...") are ignored; use
+// ParseBlockID for them.
+func ParseID(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if id, ok := matchID(s); ok {
+		return id, nil
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Path == "" && u.Host == "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidID, s)
+	}
+	if id, ok := matchID(u.Query().Get("p")); ok {
+		return id, nil
+	}
+	if id, ok := matchID(strings.TrimRight(u.Path, "/")); ok {
+		return id, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidID, s)
+}
+
+// ParseBlockID extracts a block ID from a Notion ID or URL. For a URL
+// linking to a block ("https://www.notion.so/Page-<page-id>#<block-id>")
+// it returns the block's ID; otherwise it returns the same ID as ParseID,
+// since pages are blocks too.
+func ParseBlockID(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if u, err := url.Parse(s); err == nil && u.Fragment != "" {
+		if id, ok := matchID(u.Fragment); ok {
+			return id, nil
+		}
+	}
+	return ParseID(s)
+}
+
+// matchID returns s in canonical form when it ends with a Notion ID that is
+// the whole string or follows a "/" or "-", as in "Page-Title-<id>".
+func matchID(s string) (string, bool) {
+	loc := uuidPattern.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return "", false
+	}
+	if loc[0] > 0 && s[loc[0]-1] != '/' && s[loc[0]-1] != '-' {
+		return "", false
+	}
+	parts := make([]string, 5)
+	for i := range parts {
+		parts[i] = strings.ToLower(s[loc[2+2*i]:loc[3+2*i]])
+	}
+	return strings.Join(parts, "-"), true
+}
+
+// NormalizeID returns a Notion ID without dashes and in lower case, so the
+// dashed and undashed forms of the same ID compare equal. It is meant for
+// keying and comparing IDs the API returned; use ParseID for IDs that may be
+// URLs.
+func NormalizeID(id string) string {
+	return strings.ToLower(strings.ReplaceAll(id, "-", ""))
+}
+
+// resolveID returns the page or database ID in a URL. Anything else,
+// including bare IDs in either form the API accepts and strings the parser
+// does not know, is passed on unchanged.
+func resolveID(s string) string {
+	return resolve(s, ParseID)
+}
+
+// resolveBlockID is resolveID for block IDs.
+func resolveBlockID(s string) string {
+	return resolve(s, ParseBlockID)
+}
+
+func resolve(s string, parse func(string) (string, error)) string {
+	if !strings.Contains(s, "/") && !strings.Contains(s, "#") {
+		return s
+	}
+	if id, err := parse(s); err == nil {
+		return id
+	}
+	return s
+}
@@ -0,0 +1,87 @@
+package notion
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openai/notion-go-agents/internal/notiontest"
+)
+
+func TestParseID(t *testing.T) {
+	const want = "1a2b3c4d-5e6f-47a8-b9c0-d1e2f3a4b5c6"
+	for _, in := range []string{
+		"1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6",
+		"1A2B3C4D-5E6F-47A8-B9C0-D1E2F3A4B5C6",
+		" 1a2b3c4d-5e6f-47a8-b9c0-d1e2f3a4b5c6\n",
+		"https://www.notion.so/Team-Roadmap-1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6?pvs=4",
+		"https://www.notion.so/acme/1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6?v=99999999999999999999999999999999",
+		"https://www.notion.so/acme/Roadmap-1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6#0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f",
+		"https://www.notion.so/acme/88888888888888888888888888888888?p=1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6&pm=s",
+		"https://acme.notion.site/Cafe-Menu-1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6/",
+		"https://docs.acme.com/Deadbeef-1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6",
+		"notion://www.notion.so/Roadmap-1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6",
+		"www.notion.so/1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6",
+	} {
+		got, err := ParseID(in)
+		if err != nil || got != want {
+			t.Errorf("ParseID(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{
+		"",
+		"roadmap",
+		"https://www.notion.so/",
+		"https://www.notion.so/Roadmap",
+		"1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c",
+		"https://www.notion.so/x1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6",
+	} {
+		if got, err := ParseID(in); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("ParseID(%q) = %q, %v; want ErrInvalidID", in, got, err)
+		}
+	}
+}
+
+func TestParseBlockID(t *testing.T) {
+	got, err := ParseBlockID("https://www.notion.so/Roadmap-1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6#0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f")
+	if err != nil || got != "0f0f0f0f-0f0f-0f0f-0f0f-0f0f0f0f0f0f" {
+		t.Fatalf("unexpected block ID %q, %v", got, err)
+	}
+	got, err = ParseBlockID("https://www.notion.so/Roadmap-1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6")
+	if err != nil || got != "1a2b3c4d-5e6f-47a8-b9c0-d1e2f3a4b5c6" {
+		t.Fatalf("expected the page ID, got %q, %v", got, err)
+	}
+}
+
+func TestClientAcceptsURLs(t *testing.T) {
+	const pageID = "1a2b3c4d-5e6f-47a8-b9c0-d1e2f3a4b5c6"
+	const blockID = "0f0f0f0f-0f0f-0f0f-0f0f-0f0f0f0f0f0f"
+	const dbID = "99999999-9999-9999-9999-999999999999"
+	srv := notiontest.New(t)
+	srv.AddPage(notiontest.Page(pageID, "Roadmap"))
+	srv.SetChildren(pageID, notiontest.TextBlock(blockID, "paragraph", "Ship it."))
+	srv.AddDatabase(notiontest.Database(dbID, "Tasks", map[string]any{"Name": map[string]any{"type": "title", "title": map[string]any{}}}),
+		notiontest.Row("r1", map[string]any{"Name": notiontest.TitleProperty("Write docs")}))
+	client := NewClient("secret", "", WithHTTPClient(srv.HTTPClient()))
+	ctx := context.Background()
+
+	pc, err := GetPageContent(ctx, client, "https://www.notion.so/acme/Roadmap-1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6?pvs=4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pc.ID != pageID || pc.Markdown != "Ship it." {
+		t.Fatalf("unexpected page %q: %q", pc.ID, pc.Markdown)
+	}
+	md, err := NewNotionMarkdownConverter(client).ConvertPageToMarkdown(ctx, "https://acme.notion.site/Roadmap-1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6")
+	if err != nil || md != "Ship it." {
+		t.Fatalf("unexpected Markdown %q, %v", md, err)
+	}
+	block, err := client.GetBlock(ctx, "https://www.notion.so/Roadmap-1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6#0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f")
+	if err != nil || block["id"] != blockID {
+		t.Fatalf("unexpected block %v, %v", block["id"], err)
+	}
+	res, err := client.QueryDatabase(ctx, "https://acme.notion.site/99999999999999999999999999999999?v=1", NotionDatabaseQueryRequest{})
+	if err != nil || len(res.Results) != 1 {
+		t.Fatalf("unexpected query result: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+// Package atomicfile writes files through a temporary file in the same
+// directory, so readers never see a partially written file.
+package atomicfile
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Write creates or replaces the file name with the content read from r,
+// creating its directory if needed.
+func Write(name string, r io.Reader) error {
+	dir := filepath.Dir(name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// WriteFile is Write for content held in memory.
+func WriteFile(name string, data []byte) error {
+	return Write(name, bytes.NewReader(data))
+}
@@ -0,0 +1,95 @@
+// Package mdsplit splits the Markdown the converter renders into headings and
+// the blocks between them.
+package mdsplit
+
+import "strings"
+
+// Part is a heading line or a block of lines separated from its neighbours
+// by blank lines.
+type Part struct {
+	// Level is 1 to 3 for a heading, matching Notion's heading blocks, or 0
+	// for a block.
+	Level int
+	// Title is a heading's text.
+	Title string
+	// Lines holds the part's lines as written. A fenced code block is one
+	// block, blank lines included.
+	Lines []string
+	// Line is the number of the part's first line, counted from 1.
+	Line int
+}
+
+// Text returns the part's lines joined, without surrounding blank space.
+func (p Part) Text() string {
+	return strings.TrimSpace(strings.Join(p.Lines, "\n"))
+}
+
+// Split splits md at "# ", "## " and "### " lines and at blank lines,
+// ignoring both inside code fences. A fence of backticks or tildes is closed
+// only by a fence of the same character at least as long, so code holding
+// shorter fences stays one block. An unclosed fence runs to the end.
+func Split(md string) []Part {
+	var out []Part
+	var block Part
+	// fence is the opening fence of the code block being read, if any.
+	var fence string
+	flush := func() {
+		if len(block.Lines) > 0 {
+			out = append(out, block)
+		}
+		block = Part{}
+	}
+	for i, line := range strings.Split(md, "\n") {
+		trimmed := strings.TrimSpace(line)
+		fenced := fence != ""
+		switch f := fenceOf(trimmed); {
+		case !fenced:
+			fence = f
+			fenced = f != ""
+		case f == trimmed && strings.HasPrefix(f, fence):
+			// The closing fence still belongs to the code block.
+			fence = ""
+		}
+		if !fenced && trimmed == "" {
+			flush()
+			continue
+		}
+		if level := headingLevel(line); !fenced && level > 0 {
+			flush()
+			out = append(out, Part{
+				Level: level,
+				Title: strings.TrimSpace(line[level+1:]),
+				Lines: []string{line},
+				Line:  i + 1,
+			})
+			continue
+		}
+		if len(block.Lines) == 0 {
+			block.Line = i + 1
+		}
+		block.Lines = append(block.Lines, line)
+	}
+	flush()
+	return out
+}
+
+// fenceOf returns the run of three or more backticks or tildes a line starts
+// with, or "" if it does not start a fence.
+func fenceOf(trimmed string) string {
+	if !strings.HasPrefix(trimmed, "```") && !strings.HasPrefix(trimmed, "~~~") {
+		return ""
+	}
+	n := len(trimmed) - len(strings.TrimLeft(trimmed, trimmed[:1]))
+	return trimmed[:n]
+}
+
+// headingLevel returns the level of a "# ", "## " or "### " line, or 0 for
+// other lines, such as "#1 priority".
+func headingLevel(line string) int {
+	for level := 1; level <= 3; level++ {
+		if strings.HasPrefix(line, strings.Repeat("#", level)+" ") {
+			return level
+		}
+	}
+	return 0
+}
@@ -0,0 +1,25 @@
+package mdsplit
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	md := "# Setup\n\n#1 priority.\n\n````md\n```\n# not a heading\n\n```\n````\n\n~~~\n# nor this\n~~~\n## Done"
+	var got []string
+	for _, p := range Split(md) {
+		got = append(got, strings.Repeat("#", p.Level)+"@"+strconv.Itoa(p.Line)+":"+strings.ReplaceAll(p.Text(), "\n", "|"))
+	}
+	want := []string{
+		"#@1:# Setup",
+		"@3:#1 priority.",
+		"@5:````md|```|# not a heading||```|````",
+		"@12:~~~|# nor this|~~~",
+		"##@15:## Done",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected parts:\n%s", strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,91 @@
+package notiontest
+
+// Text returns a plain rich text array containing a single text item.
+func Text(s string) []any {
+	return []any{TextItem(s)}
+}
+
+// TextItem returns a single plain rich text item.
+func TextItem(s string) map[string]any {
+	return map[string]any{
+		"type":        "text",
+		"text":        map[string]any{"content": s},
+		"plain_text":  s,
+		"annotations": map[string]any{},
+	}
+}
+
+// Page returns a page object with the given ID and title.
+func Page(id, title string) map[string]any {
+	return map[string]any{
+		"object":           "page",
+		"id":               id,
+		"url":              "https://www.notion.so/" + id,
+		"created_time":     "2024-01-01T00:00:00.000Z",
+		"last_edited_time": "2024-01-01T00:00:00.000Z",
+		"parent":           map[string]any{"type": "workspace", "workspace": true},
+		"properties": map[string]any{
+			"title": map[string]any{"id": "title", "type": "title", "title": Text(title)},
+		},
+	}
+}
+
+// Block returns a block of the given type whose payload is data.
+func Block(id, blockType string, data map[string]any) map[string]any {
+	return map[string]any{
+		"object":       "block",
+		"id":           id,
+		"type":         blockType,
+		"has_children": false,
+		blockType:      data,
+	}
+}
+
+// TextBlock returns a block of the given type containing plain text, such as
+// a paragraph, heading or list item.
+func TextBlock(id, blockType, text string) map[string]any {
+	return Block(id, blockType, map[string]any{"rich_text": Text(text)})
+}
+
+// WithChildren marks a block as having children.
+func WithChildren(block map[string]any) map[string]any {
+	block["has_children"] = true
+	return block
+}
+
+// Database returns a database object with the given ID, title and property
+// schema.
+func Database(id, title string, properties map[string]any) map[string]any {
+	return map[string]any{
+		"object":           "database",
+		"id":               id,
+		"url":              "https://www.notion.so/" + id,
+		"title":            Text(title),
+		"created_time":     "2024-01-01T00:00:00.000Z",
+		"last_edited_time": "2024-01-01T00:00:00.000Z",
+		"parent":           map[string]any{"type": "workspace", "workspace": true},
+		"properties":       properties,
+	}
+}
+
+// Row returns a database row page with the given property values.
+func Row(id string, properties map[string]any) map[string]any {
+	return map[string]any{
+		"object":           "page",
+		"id":               id,
+		"url":              "https://www.notion.so/" + id,
+		"created_time":     "2024-01-01T00:00:00.000Z",
+		"last_edited_time": "2024-01-01T00:00:00.000Z",
+		"properties":       properties,
+	}
+}
+
+// TitleProperty returns a title property value.
+func TitleProperty(s string) map[string]any {
+	return map[string]any{"type": "title", "title": Text(s)}
+}
+
+// SelectProperty returns a select property value.
+func SelectProperty(name string) map[string]any {
+	return map[string]any{"type": "select", "select": map[string]any{"name": name}}
+}
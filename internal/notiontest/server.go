@@ -0,0 +1,487 @@
+// Package notiontest provides an in-memory fake of the Notion REST API for
+// tests. It serves the endpoints used by this module from fixtures built with
+// the helpers in this package.
+package notiontest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Server is a fake Notion API backed by in-memory pages and blocks. Search
+// matches page and database titles by substring.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	pages     map[string]map[string]any
+	children  map[string][]map[string]any
+	databases map[string]map[string]any
+	rows      map[string][]map[string]any
+	files     map[string]string
+	requests  map[string]int
+	failures  map[string]Failure
+	// created numbers the IDs of pages and blocks created through the API.
+	created int
+}
+
+// New starts a fake Notion API server that is closed when the test ends.
+func New(t testing.TB) *Server {
+	s := &Server{
+		pages:     make(map[string]map[string]any),
+		children:  make(map[string][]map[string]any),
+		databases: make(map[string]map[string]any),
+		rows:      make(map[string][]map[string]any),
+		files:     make(map[string]string),
+		requests:  make(map[string]int),
+		failures:  make(map[string]Failure),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// HTTPClient returns an HTTP client that sends requests addressed to
+// api.notion.com to the fake server instead.
+func (s *Server) HTTPClient() *http.Client {
+	target, _ := url.Parse(s.URL)
+	return &http.Client{Transport: rewriteTransport{target: target}}
+}
+
+// AddPage registers a page object. The page must have an "id" field.
+func (s *Server) AddPage(page map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := page["id"].(string)
+	if _, ok := page["object"]; !ok {
+		page["object"] = "page"
+	}
+	s.pages[id] = page
+}
+
+// RemovePage deletes a page, as if it had been permanently deleted, so it is
+// no longer found by ID or search.
+func (s *Server) RemovePage(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pages, id)
+}
+
+// AddDatabase registers a database object and its rows. The rows are also
+// served as pages. Queries return every row regardless of filters.
+func (s *Server) AddDatabase(db map[string]any, rows ...map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := db["id"].(string)
+	if _, ok := db["object"]; !ok {
+		db["object"] = "database"
+	}
+	s.databases[id] = db
+	s.rows[id] = rows
+	for _, row := range rows {
+		if _, ok := row["object"]; !ok {
+			row["object"] = "page"
+		}
+		row["parent"] = map[string]any{"type": "database_id", "database_id": id}
+		rowID, _ := row["id"].(string)
+		s.pages[rowID] = row
+	}
+}
+
+// SetFile serves content at urlPath on any host, standing in for files hosted
+// on Notion's file storage.
+func (s *Server) SetFile(urlPath, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[urlPath] = content
+}
+
+// SetChildren sets the child blocks returned for a page or block ID.
+func (s *Server) SetChildren(parentID string, blocks ...map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.children[parentID] = blocks
+}
+
+// Failure is an error response served in place of the normal one.
+type Failure struct {
+	Status  int
+	Code    string
+	Message string
+	// After is how many matching requests are answered normally before the
+	// failures start.
+	After int
+}
+
+// SetFailure makes requests for the given method and path, for example
+// "GET /v1/databases/abc", fail with f.
+func (s *Server) SetFailure(key string, f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[key] = f
+}
+
+// Requests reports how many requests were made for the given method and path,
+// for example "GET /v1/pages/abc".
+func (s *Server) Requests(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[key]
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := r.Method + " " + r.URL.Path
+	s.requests[key]++
+	if f, ok := s.failures[key]; ok && s.requests[key] > f.After {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(f.Status)
+		_ = json.NewEncoder(w).Encode(map[string]any{"object": "error", "status": f.Status, "code": f.Code, "message": f.Message})
+		return
+	}
+
+	if content, ok := s.files[r.URL.Path]; ok {
+		_, _ = w.Write([]byte(content))
+		return
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 3 && parts[0] == "v1" && parts[1] == "pages" && r.Method == http.MethodGet:
+		pg, ok := s.pages[parts[2]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "object_not_found")
+			return
+		}
+		writeJSON(w, pg)
+	case len(parts) == 2 && parts[0] == "v1" && parts[1] == "pages" && r.Method == http.MethodPost:
+		var req struct {
+			Parent     map[string]any   `json:"parent"`
+			Properties map[string]any   `json:"properties"`
+			Children   []map[string]any `json:"children"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_json")
+			return
+		}
+		writeJSON(w, s.createPage(req.Parent, req.Properties, req.Children))
+	case len(parts) == 3 && parts[0] == "v1" && parts[1] == "pages" && r.Method == http.MethodPatch:
+		pg, ok := s.pages[parts[2]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "object_not_found")
+			return
+		}
+		var req struct {
+			Properties map[string]any `json:"properties"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_json")
+			return
+		}
+		fillProperties(req.Properties)
+		props, _ := pg["properties"].(map[string]any)
+		if props == nil {
+			props = make(map[string]any)
+			pg["properties"] = props
+		}
+		for name, v := range req.Properties {
+			props[name] = v
+		}
+		writeJSON(w, pg)
+	case len(parts) == 4 && parts[0] == "v1" && parts[1] == "blocks" && parts[3] == "children" && r.Method == http.MethodPatch:
+		if _, ok := s.pages[parts[2]]; !ok && s.block(parts[2]) == nil {
+			writeError(w, http.StatusNotFound, "object_not_found")
+			return
+		}
+		var req struct {
+			Children []map[string]any `json:"children"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_json")
+			return
+		}
+		added := s.appendChildren(parts[2], req.Children)
+		results := make([]any, len(added))
+		for i, b := range added {
+			results[i] = b
+		}
+		writeJSON(w, map[string]any{"object": "list", "results": results, "has_more": false, "next_cursor": nil})
+	case len(parts) == 3 && parts[0] == "v1" && parts[1] == "blocks" && r.Method == http.MethodGet:
+		block := s.block(parts[2])
+		if block == nil {
+			writeError(w, http.StatusNotFound, "object_not_found")
+			return
+		}
+		writeJSON(w, block)
+	case len(parts) == 4 && parts[0] == "v1" && parts[1] == "blocks" && parts[3] == "children" && r.Method == http.MethodGet:
+		if _, ok := s.children[parts[2]]; !ok && s.pages[parts[2]] == nil && s.block(parts[2]) == nil {
+			writeError(w, http.StatusNotFound, "object_not_found")
+			return
+		}
+		s.writeList(w, r.URL.Query().Get("start_cursor"), r.URL.Query().Get("page_size"), s.children[parts[2]])
+	case len(parts) == 3 && parts[0] == "v1" && parts[1] == "databases" && r.Method == http.MethodGet:
+		db, ok := s.databases[parts[2]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "object_not_found")
+			return
+		}
+		writeJSON(w, db)
+	case len(parts) == 4 && parts[0] == "v1" && parts[1] == "databases" && parts[3] == "query" && r.Method == http.MethodPost:
+		if _, ok := s.databases[parts[2]]; !ok {
+			writeError(w, http.StatusNotFound, "object_not_found")
+			return
+		}
+		var req struct {
+			StartCursor string `json:"start_cursor"`
+			PageSize    int    `json:"page_size"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		s.writeList(w, req.StartCursor, strconv.Itoa(req.PageSize), s.rows[parts[2]])
+	case len(parts) == 2 && parts[0] == "v1" && parts[1] == "search" && r.Method == http.MethodPost:
+		var req struct {
+			Query  string `json:"query"`
+			Filter *struct {
+				Value string `json:"value"`
+			} `json:"filter"`
+			Sort *struct {
+				Direction string `json:"direction"`
+			} `json:"sort"`
+			StartCursor string `json:"start_cursor"`
+			PageSize    int    `json:"page_size"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		objectType := ""
+		if req.Filter != nil {
+			objectType = req.Filter.Value
+		}
+		results := s.search(req.Query, objectType)
+		if req.Sort != nil {
+			sortByEditTime(results, req.Sort.Direction == "ascending")
+		}
+		s.writeList(w, req.StartCursor, strconv.Itoa(req.PageSize), results)
+	default:
+		writeError(w, http.StatusNotFound, "invalid_request_url")
+	}
+}
+
+// createPage stores a page created through the API under its parent: as a
+// row of a database parent, or as a child_page block of a page parent.
+func (s *Server) createPage(parent, properties map[string]any, children []map[string]any) map[string]any {
+	s.created++
+	id := "created-" + strconv.Itoa(s.created)
+	fillProperties(properties)
+	pg := map[string]any{
+		"object":           "page",
+		"id":               id,
+		"url":              "https://www.notion.so/" + id,
+		"created_time":     "2024-01-01T00:00:00.000Z",
+		"last_edited_time": "2024-01-01T00:00:00.000Z",
+		"parent":           parent,
+		"properties":       properties,
+	}
+	s.pages[id] = pg
+	if dbID, _ := parent["database_id"].(string); dbID != "" {
+		s.rows[dbID] = append(s.rows[dbID], pg)
+	} else if pageID, _ := parent["page_id"].(string); pageID != "" {
+		title := objectTitle(map[string]any{"properties": properties})
+		s.children[pageID] = append(s.children[pageID], Block(id, "child_page", map[string]any{"title": title}))
+	}
+	s.appendChildren(id, children)
+	return pg
+}
+
+// appendChildren stores blocks sent to the API as children of parentID,
+// giving them IDs, and returns them.
+func (s *Server) appendChildren(parentID string, blocks []map[string]any) []map[string]any {
+	for _, b := range blocks {
+		s.created++
+		b["object"] = "block"
+		b["id"] = "created-" + strconv.Itoa(s.created)
+		b["has_children"] = false
+		fillPlainText(b)
+		s.children[parentID] = append(s.children[parentID], b)
+	}
+	return blocks
+}
+
+// fillProperties completes property values sent to the API as the API
+// returns them, with their type and the plain_text of rich text.
+func fillProperties(props map[string]any) {
+	for _, v := range props {
+		prop, _ := v.(map[string]any)
+		if _, ok := prop["type"]; ok || len(prop) != 1 {
+			continue
+		}
+		var typ string
+		for k := range prop {
+			typ = k
+		}
+		prop["type"] = typ
+	}
+	fillPlainText(props)
+}
+
+// fillPlainText sets the plain_text of the rich text items in v that were
+// sent to the API with only their content, as the API does in responses.
+func fillPlainText(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		if text, ok := v["text"].(map[string]any); ok && v["type"] == "text" {
+			if _, ok := v["plain_text"]; !ok {
+				v["plain_text"], _ = text["content"].(string)
+			}
+		}
+		for _, child := range v {
+			fillPlainText(child)
+		}
+	case []any:
+		for _, child := range v {
+			fillPlainText(child)
+		}
+	case []map[string]any:
+		for _, child := range v {
+			fillPlainText(child)
+		}
+	}
+}
+
+// block returns a copy of the block with the given ID, with its parent set
+// from the page or block whose children include it.
+func (s *Server) block(id string) map[string]any {
+	for parentID, blocks := range s.children {
+		for _, b := range blocks {
+			if b["id"] != id {
+				continue
+			}
+			out := make(map[string]any, len(b)+1)
+			for k, v := range b {
+				out[k] = v
+			}
+			parentType := "block_id"
+			if _, ok := s.pages[parentID]; ok {
+				parentType = "page_id"
+			}
+			out["parent"] = map[string]any{"type": parentType, parentType: parentID}
+			return out
+		}
+	}
+	return nil
+}
+
+// search returns the pages and databases whose title contains query, ignoring
+// case, ordered by ID. Archived objects are left out. An objectType of "page"
+// or "database" restricts the results to that kind of object.
+func (s *Server) search(query, objectType string) []map[string]any {
+	query = strings.ToLower(query)
+	var results []map[string]any
+	for _, objects := range []map[string]map[string]any{s.pages, s.databases} {
+		for _, obj := range objects {
+			if (objectType != "" && obj["object"] != objectType) || obj["archived"] == true {
+				continue
+			}
+			if strings.Contains(strings.ToLower(objectTitle(obj)), query) {
+				results = append(results, obj)
+			}
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, _ := results[i]["id"].(string)
+		b, _ := results[j]["id"].(string)
+		return a < b
+	})
+	return results
+}
+
+// sortByEditTime orders search results by last_edited_time, newest first
+// unless ascending is set. Ties keep their order.
+func sortByEditTime(results []map[string]any, ascending bool) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, _ := results[i]["last_edited_time"].(string)
+		b, _ := results[j]["last_edited_time"].(string)
+		if ascending {
+			return a < b
+		}
+		return a > b
+	})
+}
+
+// objectTitle returns the plain text title of a page or database object.
+func objectTitle(obj map[string]any) string {
+	title, _ := obj["title"].([]any)
+	if props, ok := obj["properties"].(map[string]any); ok && title == nil {
+		for _, p := range props {
+			prop, _ := p.(map[string]any)
+			if prop["type"] == "title" {
+				title, _ = prop["title"].([]any)
+			}
+		}
+	}
+	var b strings.Builder
+	for _, item := range title {
+		m, _ := item.(map[string]any)
+		text, _ := m["plain_text"].(string)
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+// writeList writes a paginated list response. Cursors are decimal offsets
+// into items.
+func (s *Server) writeList(w http.ResponseWriter, cursor, pageSize string, items []map[string]any) {
+	start, _ := strconv.Atoi(cursor)
+	size, _ := strconv.Atoi(pageSize)
+	if size <= 0 || size > 100 {
+		size = 100
+	}
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + size
+	if end > len(items) {
+		end = len(items)
+	}
+	results := make([]any, 0, end-start)
+	for _, it := range items[start:end] {
+		results = append(results, it)
+	}
+	var next any
+	if end < len(items) {
+		next = strconv.Itoa(end)
+	}
+	writeJSON(w, map[string]any{
+		"object":      "list",
+		"results":     results,
+		"has_more":    end < len(items),
+		"next_cursor": next,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"object": "error", "status": status, "code": code})
+}
+
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.URL.Scheme = t.target.Scheme
+	r.URL.Host = t.target.Host
+	r.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
@@ -0,0 +1,186 @@
+package notion
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// ColorMode controls how block and text colors are rendered.
+type ColorMode int
+
+const (
+	// ColorNone drops colors.
+	ColorNone ColorMode = iota
+	// ColorHTML wraps colored text in <span style="..."> elements.
+	ColorHTML
+	// ColorAttributes wraps colored text in bracketed spans with attributes,
+	// as in [text]{color="red"}, understood by Pandoc and similar tools.
+	ColorAttributes
+)
+
+// ToggleMode controls how toggle blocks and toggleable headings are rendered.
+type ToggleMode int
+
+const (
+	// TogglePlain renders toggles as list items and toggleable headings as
+	// ordinary headings followed by their content.
+	TogglePlain ToggleMode = iota
+	// ToggleDetails renders toggles and toggleable headings as HTML
+	// <details> elements with the toggle text as the summary.
+	ToggleDetails
+	// ToggleSections renders toggleable headings as sections whose content
+	// headings are demoted below the toggle heading.
+	ToggleSections
+)
+
+// ColumnMode controls how column_list blocks are rendered.
+type ColumnMode int
+
+const (
+	// ColumnsFlatten renders the content of each column one after another.
+	ColumnsFlatten ColumnMode = iota
+	// ColumnsSeparated renders columns one after another, each introduced by
+	// an HTML comment naming its position.
+	ColumnsSeparated
+	// ColumnsHTML renders columns side by side in an HTML table.
+	ColumnsHTML
+)
+
+// WithColors preserves block and rich text colors using the given mode.
+func WithColors(mode ColorMode) ConverterOption {
+	return func(c *NotionMarkdownConverter) {
+		c.colors = mode
+	}
+}
+
+// WithToggles sets how toggles and toggleable headings are rendered.
+func WithToggles(mode ToggleMode) ConverterOption {
+	return func(c *NotionMarkdownConverter) {
+		c.toggles = mode
+	}
+}
+
+// WithColumns sets how column layouts are rendered.
+func WithColumns(mode ColumnMode) ConverterOption {
+	return func(c *NotionMarkdownConverter) {
+		c.columns = mode
+	}
+}
+
+// blockText renders the rich text of a text block, escaped for the start of
+// a block and wrapped in the block's color.
+func (c *NotionMarkdownConverter) blockText(blockData any) string {
+	text := escapeBlockStart(c.extractRichText(blockData))
+	if text == "" {
+		return ""
+	}
+	data, _ := blockData.(map[string]any)
+	return c.colorize(text, data)
+}
+
+// colorize wraps text in the color of a block's payload, if any.
+func (c *NotionMarkdownConverter) colorize(text string, data map[string]any) string {
+	color, _ := data["color"].(string)
+	return c.colorizeText(text, color)
+}
+
+// colorizeText wraps text in a Notion color such as "red" or
+// "yellow_background". The default color leaves text unchanged.
+func (c *NotionMarkdownConverter) colorizeText(text, color string) string {
+	if c.colors == ColorNone || color == "" || color == "default" {
+		return text
+	}
+	property := "color"
+	if name, ok := strings.CutSuffix(color, "_background"); ok {
+		property, color = "background-color", name
+	}
+	if c.colors == ColorAttributes {
+		attr := "color"
+		if property != "color" {
+			attr = "background"
+		}
+		return "[" + text + "]{" + attr + "=\"" + color + "\"}"
+	}
+	return "<span style=\"" + property + ": " + color + "\">" + text + "</span>"
+}
+
+// isToggle reports whether a block is a toggle or a toggleable heading.
+func isToggle(block map[string]any) bool {
+	blockType, _ := block["type"].(string)
+	if blockType == "toggle" {
+		return true
+	}
+	if headingLevel(blockType) == 0 {
+		return false
+	}
+	data, _ := block[blockType].(map[string]any)
+	toggleable, _ := data["is_toggleable"].(bool)
+	return toggleable
+}
+
+// headingLevel returns 1 to 3 for heading blocks and 0 for other types.
+func headingLevel(blockType string) int {
+	switch blockType {
+	case "heading_1":
+		return 1
+	case "heading_2":
+		return 2
+	case "heading_3":
+		return 3
+	}
+	return 0
+}
+
+// renderDetailsToMarkdown renders a toggle as an HTML <details> element. The
+// summary is HTML, so it holds escaped plain text rather than Markdown.
+func (c *NotionMarkdownConverter) renderDetailsToMarkdown(node BlockNode, st renderState) string {
+	blockType, _ := node.Block["type"].(string)
+	data, _ := node.Block[blockType].(map[string]any)
+	richText, _ := data["rich_text"].([]any)
+	summary := html.EscapeString(strings.TrimSpace(rawRichText(richText)))
+	if level := headingLevel(blockType); level > 0 {
+		level += st.headingShift
+		if level > 6 {
+			level = 6
+		}
+		tag := "h" + strconv.Itoa(level)
+		summary = "<" + tag + ">" + summary + "</" + tag + ">"
+	}
+	out := "<details>\n<summary>" + summary + "</summary>"
+	children := c.renderBlocksToMarkdown(node.Children, renderState{headingShift: st.headingShift})
+	if children != "" {
+		out += "\n\n" + children
+	}
+	return out + "\n\n</details>"
+}
+
+// renderColumnsToMarkdown renders a column_list in the ColumnsSeparated or
+// ColumnsHTML layout.
+func (c *NotionMarkdownConverter) renderColumnsToMarkdown(node BlockNode, st renderState) string {
+	var columns []string
+	for _, column := range node.Children {
+		columns = append(columns, c.renderBlocksToMarkdown(column.Children, renderState{headingShift: st.headingShift}))
+	}
+	if len(columns) == 0 {
+		return ""
+	}
+	var parts []string
+	if c.columns == ColumnsHTML {
+		parts = append(parts, "<table>\n<tr>")
+		for _, col := range columns {
+			parts = append(parts, "<td valign=\"top\">", col, "</td>")
+		}
+		parts = append(parts, "</tr>\n</table>")
+		return strings.Join(parts, "\n\n")
+	}
+	n := strconv.Itoa(len(columns))
+	for i, col := range columns {
+		parts = append(parts, "<!-- column "+strconv.Itoa(i+1)+" of "+n+" -->")
+		if col != "" {
+			parts = append(parts, col)
+		}
+	}
+	parts = append(parts, "<!-- end of columns -->")
+	return strings.Join(parts, "\n\n")
+}
@@ -3,11 +3,15 @@ package notion
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 )
 
 // NotionMarkdownConverter turns Notion block trees into Markdown text.
@@ -15,37 +19,240 @@ type NotionMarkdownConverter struct {
 	client   *Client
 	maxDepth int
 	maxNodes int
+
+	// titles caches page titles looked up to label page mentions and
+	// link_to_page blocks. An empty string records a failed lookup.
+	titlesMu sync.Mutex
+	titles   map[string]string
+
+	// childDatabaseRows is the maximum number of rows queried and rendered
+	// for each child_database block; 0 disables querying.
+	childDatabaseRows int
+
+	childPages        ChildPageMode
+	maxChildPageDepth int
+	maxChildPages     int
+
+	assets      AssetStore
+	assetErrors func(blockID string, err error)
+	frontMatter bool
+	sourceMap   bool
+
+	colors  ColorMode
+	toggles ToggleMode
+	columns ColumnMode
+
+	alignTables         bool
+	maxTableColumnWidth int
+	wideTableColumns    int
 }
 
-func NewNotionMarkdownConverter(client *Client) *NotionMarkdownConverter {
-	return &NotionMarkdownConverter{
-		client:   client,
-		maxDepth: 3,
-		maxNodes: 500,
+// ChildPageMode controls how child_page blocks are rendered.
+type ChildPageMode int
+
+const (
+	// ChildPageHeading renders a child page as a heading with its title.
+	ChildPageHeading ChildPageMode = iota
+	// ChildPageLink renders a child page as a link that includes its page ID,
+	// without fetching its content.
+	ChildPageLink
+	// ChildPageInline fetches child pages recursively and inlines their content
+	// under a heading, demoting the headings inside each page.
+	ChildPageInline
+)
+
+// ConverterOption configures a NotionMarkdownConverter.
+type ConverterOption func(*NotionMarkdownConverter)
+
+// WithChildDatabases makes the converter query inline child databases and
+// render up to rowLimit rows of each as a Markdown table. A rowLimit of 0
+// (the default) renders only the database title.
+func WithChildDatabases(rowLimit int) ConverterOption {
+	return func(c *NotionMarkdownConverter) {
+		c.childDatabaseRows = rowLimit
 	}
 }
 
+// WithChildPages sets how child_page blocks are rendered. The default is
+// ChildPageHeading.
+func WithChildPages(mode ChildPageMode) ConverterOption {
+	return func(c *NotionMarkdownConverter) {
+		c.childPages = mode
+	}
+}
+
+// WithChildPageLimits bounds ChildPageInline expansion to maxDepth levels of
+// nested pages and maxPages pages in total. Child pages beyond either budget
+// are rendered as links. Non-positive values keep the defaults of 2 and 25.
+func WithChildPageLimits(maxDepth, maxPages int) ConverterOption {
+	return func(c *NotionMarkdownConverter) {
+		if maxDepth > 0 {
+			c.maxChildPageDepth = maxDepth
+		}
+		if maxPages > 0 {
+			c.maxChildPages = maxPages
+		}
+	}
+}
+
+func NewNotionMarkdownConverter(client *Client, opts ...ConverterOption) *NotionMarkdownConverter {
+	c := &NotionMarkdownConverter{
+		client:            client,
+		maxDepth:          3,
+		maxNodes:          500,
+		titles:            make(map[string]string),
+		maxChildPageDepth: 2,
+		maxChildPages:     25,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
 type BlockNode struct {
 	Block    map[string]any
 	Children []BlockNode
+
+	// database holds the queried rows of a child_database block.
+	database *databaseTable
+	// assetURL replaces the URL of a Notion-hosted file once it has been
+	// copied to the converter's AssetStore.
+	assetURL string
+	// pageDepth is the nesting level of a child_page block whose content was
+	// inlined in Children; 0 means the page was not expanded.
+	pageDepth int
+}
+
+// fetchState tracks child page expansion during a single conversion.
+type fetchState struct {
+	pageDepth int
+	pages     int
+	visited   map[string]bool
+}
+
+// renderState carries the layout context of the blocks being rendered.
+type renderState struct {
+	// headingShift demotes headings by this many levels, for content of
+	// inlined child pages.
+	headingShift int
+	// number is the position of a numbered list item within its list.
+	number int
+}
+
+// databaseTable is the printable content of a queried child database.
+type databaseTable struct {
+	columns   []string
+	rows      [][]string
+	truncated bool
+	// linked marks a linked database view, which the API cannot retrieve.
+	linked bool
+	// err is why the database, or the rows after those in rows, could not
+	// be loaded.
+	err error
 }
 
-// ConvertPageToMarkdown retrieves blocks for a page and renders them to Markdown.
+// ConvertPageToMarkdown retrieves blocks for a page and renders them to
+// Markdown. Like the other page methods, it accepts a page ID or URL.
 func (c *NotionMarkdownConverter) ConvertPageToMarkdown(ctx context.Context, pageID string) (string, error) {
-	blocks, err := c.getBlockTree(ctx, pageID, 0)
+	return c.convertPage(ctx, pageID, nil)
+}
+
+// WritePageMarkdown renders a page to w as its blocks are fetched, so output
+// starts before the whole page has been retrieved and the page is never held
+// in memory as a whole. The output is the same as ConvertPageToMarkdown's.
+// If fetching fails part way, w holds the output written so far.
+// When the client has a Cache, pages are built in full so they can be
+// stored, and are written in one piece.
+func (c *NotionMarkdownConverter) WritePageMarkdown(ctx context.Context, w io.Writer, pageID string) error {
+	return c.writePage(ctx, w, pageID, nil)
+}
+
+// convertPage renders a page, reusing pg for front matter when the caller
+// has already fetched it.
+func (c *NotionMarkdownConverter) convertPage(ctx context.Context, pageID string, pg *NotionPage) (string, error) {
+	var b strings.Builder
+	if err := c.writePage(ctx, &b, pageID, pg); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func (c *NotionMarkdownConverter) writePage(ctx context.Context, w io.Writer, pageID string, pg *NotionPage) error {
+	pageID = resolveID(pageID)
+	cache := c.cache()
+	if (c.frontMatter || cache != nil) && pg == nil {
+		var err error
+		if pg, err = c.client.GetPage(ctx, pageID); err != nil {
+			return err
+		}
+	}
+	if cache != nil && pg.LastEditedTime != "" {
+		return c.writeCachedPage(ctx, w, cache, pageID, pg)
+	}
+	if c.frontMatter {
+		if _, err := io.WriteString(w, FrontMatter(pg)+"\n"); err != nil {
+			return err
+		}
+	}
+	st := &fetchState{visited: map[string]bool{NormalizeID(pageID): true}}
+	var seq blockSequence
+	// Whitespace at the end of each chunk is held back until more text
+	// follows, so the streamed page is trimmed like the string one.
+	var pending string
+	var started bool
+	var writeErr error
+	err := c.forEachBlockChild(ctx, pageID, func(child map[string]any) error {
+		c.resolveLinkedTitles(ctx, []map[string]any{child})
+		node, err := c.blockNode(ctx, child, 0, st)
+		if err != nil {
+			return err
+		}
+		md := c.nextBlockMarkdown(&seq, node, renderState{})
+		if !started {
+			md = strings.TrimLeftFunc(md, unicode.IsSpace)
+		}
+		text := strings.TrimRightFunc(md, unicode.IsSpace)
+		if text == "" {
+			pending += md
+			return nil
+		}
+		if _, writeErr = io.WriteString(w, pending+text); writeErr != nil {
+			return writeErr
+		}
+		started = true
+		pending = md[len(text):]
+		return nil
+	})
+	if writeErr != nil {
+		return writeErr
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to get block tree: %w", err)
+		return fmt.Errorf("failed to get block tree: %w", err)
 	}
-	var b strings.Builder
-	c.renderBlocksToMarkdown(&b, blocks, 0)
-	md := strings.TrimSpace(b.String())
-	if md == "" {
-		md = "(no textual content)"
+	if !started {
+		_, err = io.WriteString(w, "(no textual content)")
 	}
-	return md, nil
+	return err
+}
+
+// GetBlockTree fetches the blocks of a page as a tree, following the
+// converter's options for nesting depth, child pages, child databases and
+// assets. Render the tree with RenderMarkdown, RenderHTML or RenderText.
+func (c *NotionMarkdownConverter) GetBlockTree(ctx context.Context, pageID string) ([]BlockNode, error) {
+	pageID = resolveID(pageID)
+	st := &fetchState{visited: map[string]bool{NormalizeID(pageID): true}}
+	return c.getBlockTree(ctx, pageID, 0, st)
+}
+
+// RenderMarkdown renders blocks fetched with GetBlockTree, or any part of such
+// a tree, as Markdown without front matter. It returns "" when the blocks have
+// no textual content.
+func (c *NotionMarkdownConverter) RenderMarkdown(nodes []BlockNode) string {
+	return strings.TrimSpace(c.renderBlocksToMarkdown(nodes, renderState{}))
 }
 
-func (c *NotionMarkdownConverter) getBlockTree(ctx context.Context, blockID string, depth int) ([]BlockNode, error) {
+func (c *NotionMarkdownConverter) getBlockTree(ctx context.Context, blockID string, depth int, st *fetchState) ([]BlockNode, error) {
 	if depth >= c.maxDepth {
 		return nil, nil
 	}
@@ -53,200 +260,397 @@ func (c *NotionMarkdownConverter) getBlockTree(ctx context.Context, blockID stri
 	if err != nil {
 		return nil, err
 	}
+	c.resolveLinkedTitles(ctx, children)
 	nodes := make([]BlockNode, 0, len(children))
 	for _, child := range children {
-		node := BlockNode{Block: child}
-		hasChildren, _ := child["has_children"].(bool)
-		blockType, _ := child["type"].(string)
-		var sourceID string
-		if blockType == "synced_block" {
-			if sb, ok := child["synced_block"].(map[string]any); ok {
-				if sf, ok := sb["synced_from"].(map[string]any); ok {
-					if bid, ok := sf["block_id"].(string); ok && bid != "" {
-						sourceID = bid
-					}
+		node, err := c.blockNode(ctx, child, depth, st)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// blockNode builds the node for a block at the given depth, fetching its
+// children and any content the converter's options call for.
+func (c *NotionMarkdownConverter) blockNode(ctx context.Context, child map[string]any, depth int, st *fetchState) (BlockNode, error) {
+	node := BlockNode{Block: child}
+	hasChildren, _ := child["has_children"].(bool)
+	blockType, _ := child["type"].(string)
+	if blockType == "child_database" && c.childDatabaseRows > 0 {
+		if id, _ := child["id"].(string); id != "" {
+			node.database = c.queryChildDatabase(ctx, id)
+		}
+	}
+	if c.assets != nil {
+		node.assetURL = c.storeAsset(ctx, child)
+	}
+	if blockType == "child_page" && c.childPages != ChildPageHeading {
+		err := c.expandChildPage(ctx, &node, st)
+		return node, err
+	}
+	var sourceID string
+	if blockType == "synced_block" {
+		if sb, ok := child["synced_block"].(map[string]any); ok {
+			if sf, ok := sb["synced_from"].(map[string]any); ok {
+				if bid, ok := sf["block_id"].(string); ok && bid != "" {
+					sourceID = bid
 				}
 			}
 		}
-		if sourceID == "" && hasChildren {
-			if id, ok := child["id"].(string); ok && id != "" {
-				sourceID = id
-			}
+	}
+	if sourceID == "" && hasChildren {
+		if id, ok := child["id"].(string); ok && id != "" {
+			sourceID = id
 		}
-		if sourceID != "" {
-			childNodes, err := c.getBlockTree(ctx, sourceID, depth+1)
-			if err != nil {
-				return nil, err
-			}
-			node.Children = childNodes
+	}
+	if sourceID != "" {
+		childNodes, err := c.getBlockTree(ctx, sourceID, depth+1, st)
+		if err != nil {
+			return node, err
 		}
-		nodes = append(nodes, node)
+		node.Children = childNodes
 	}
-	return nodes, nil
+	return node, nil
+}
+
+// expandChildPage fetches the content of a child_page block in
+// ChildPageInline mode, unless the page was already expanded during this
+// conversion or the depth or page budget is spent.
+func (c *NotionMarkdownConverter) expandChildPage(ctx context.Context, node *BlockNode, st *fetchState) error {
+	if c.childPages != ChildPageInline {
+		return nil
+	}
+	id, _ := node.Block["id"].(string)
+	key := NormalizeID(id)
+	if id == "" || st.visited[key] || st.pageDepth >= c.maxChildPageDepth || st.pages >= c.maxChildPages {
+		return nil
+	}
+	st.visited[key] = true
+	st.pages++
+	st.pageDepth++
+	children, err := c.getBlockTree(ctx, id, 0, st)
+	st.pageDepth--
+	if err != nil {
+		return err
+	}
+	node.Children = children
+	node.pageDepth = st.pageDepth + 1
+	return nil
 }
 
 func (c *NotionMarkdownConverter) getAllBlockChildren(ctx context.Context, blockID string) ([]map[string]any, error) {
-	path := "/v1/blocks/" + blockID + "/children?page_size=100"
 	var results []map[string]any
+	err := c.forEachBlockChild(ctx, blockID, func(block map[string]any) error {
+		results = append(results, block)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// forEachBlockChild calls fn for each child of a block, fetching the children
+// one page of results at a time and stopping after maxNodes children.
+func (c *NotionMarkdownConverter) forEachBlockChild(ctx context.Context, blockID string, fn func(map[string]any) error) error {
+	count := 0
 	cursor := ""
 	for {
-		p := path
-		if cursor != "" {
-			p = p + "&start_cursor=" + cursor
-		}
-		resp, err := c.client.request(ctx, http.MethodGet, p, nil)
+		page, err := c.getBlockChildrenPage(ctx, blockID, cursor)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		defer resp.Body.Close()
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			return nil, fmt.Errorf("get children failed: status=%d body=%s", resp.StatusCode, string(body))
-		}
-		var blocksResp struct {
-			Object     string            `json:"object"`
-			Results    []json.RawMessage `json:"results"`
-			NextCursor string            `json:"next_cursor"`
-			HasMore    bool              `json:"has_more"`
-		}
-		if err := json.NewDecoder(resp.Body).Decode(&blocksResp); err != nil {
-			return nil, fmt.Errorf("failed to decode blocks: %w", err)
-		}
-		for _, raw := range blocksResp.Results {
+		for _, raw := range page.Results {
 			var blockMap map[string]any
 			if err := json.Unmarshal(raw, &blockMap); err != nil {
 				continue
 			}
-			results = append(results, blockMap)
-			if len(results) >= c.maxNodes {
-				return results, nil
+			if err := fn(blockMap); err != nil {
+				return err
+			}
+			count++
+			if count >= c.maxNodes {
+				return nil
 			}
 		}
-		if !blocksResp.HasMore || blocksResp.NextCursor == "" {
-			break
+		if !page.HasMore || page.NextCursor == "" {
+			return nil
 		}
-		cursor = blocksResp.NextCursor
+		cursor = page.NextCursor
 	}
-	c.processNumberedListItems(results)
-	return results, nil
 }
 
-func (c *NotionMarkdownConverter) processNumberedListItems(blocks []map[string]any) {
-	numberedListIndex := 0
-	for _, block := range blocks {
-		blockType, _ := block["type"].(string)
-		if blockType == "numbered_list_item" {
-			numberedListIndex++
-			if numberedItem, ok := block["numbered_list_item"].(map[string]any); ok {
-				numberedItem["number"] = numberedListIndex
-			}
-		} else {
-			numberedListIndex = 0
+type blockChildrenPage struct {
+	Object     string            `json:"object"`
+	Results    []json.RawMessage `json:"results"`
+	NextCursor string            `json:"next_cursor"`
+	HasMore    bool              `json:"has_more"`
+}
+
+func (c *NotionMarkdownConverter) getBlockChildrenPage(ctx context.Context, blockID, cursor string) (*blockChildrenPage, error) {
+	p := "/v1/blocks/" + blockID + "/children?page_size=100"
+	if cursor != "" {
+		p = p + "&start_cursor=" + cursor
+	}
+	resp, err := c.client.request(ctx, http.MethodGet, p, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError("get children", resp)
+	}
+	var blocksResp blockChildrenPage
+	if err := json.NewDecoder(resp.Body).Decode(&blocksResp); err != nil {
+		return nil, fmt.Errorf("failed to decode blocks: %w", err)
+	}
+	return &blocksResp, nil
+}
+
+// blockSequence tracks the blocks already written at one nesting level so
+// that siblings are separated and numbered the way CommonMark expects: items
+// of the same list are kept together, everything else is separated by a blank
+// line, and numbered lists restart after any other block.
+type blockSequence struct {
+	wrote    bool
+	prevList string
+	number   int
+}
+
+// nextNumber returns the number a numbered list item would get if written next.
+func (s *blockSequence) nextNumber() int {
+	if s.prevList != "numbered" {
+		return 1
+	}
+	return s.number + 1
+}
+
+// advance records that a block of the given list kind ("" for blocks that
+// are not list items) is written next and returns the separator to write
+// before it.
+func (s *blockSequence) advance(kind string) string {
+	sep := ""
+	if s.wrote {
+		sep = "\n\n"
+		if kind != "" && kind == s.prevList {
+			sep = "\n"
 		}
 	}
+	if kind == "numbered" {
+		s.number = s.nextNumber()
+	}
+	s.prevList = kind
+	s.wrote = true
+	return sep
 }
 
-func (c *NotionMarkdownConverter) renderBlocksToMarkdown(b *strings.Builder, nodes []BlockNode, indent int) {
-	for i, node := range nodes {
-		c.renderBlockToMarkdown(b, node, indent)
-		if i < len(nodes)-1 {
-			b.WriteString("\n")
+// listKind groups block types that render as items of the same Markdown list.
+func (c *NotionMarkdownConverter) listKind(blockType string) string {
+	switch blockType {
+	case "toggle":
+		if c.toggles == ToggleDetails {
+			return ""
 		}
+		return "bullet"
+	case "bulleted_list_item", "to_do":
+		return "bullet"
+	case "numbered_list_item":
+		return "numbered"
+	}
+	return ""
+}
+
+func (c *NotionMarkdownConverter) renderBlocksToMarkdown(nodes []BlockNode, st renderState) string {
+	var b strings.Builder
+	var seq blockSequence
+	for _, node := range nodes {
+		b.WriteString(c.nextBlockMarkdown(&seq, node, st))
 	}
+	return b.String()
 }
 
-func (c *NotionMarkdownConverter) renderBlockToMarkdown(b *strings.Builder, node BlockNode, indent int) {
+// nextBlockMarkdown renders node as the next sibling in seq and returns it
+// preceded by its separator, or "" if the block renders nothing.
+func (c *NotionMarkdownConverter) nextBlockMarkdown(seq *blockSequence, node BlockNode, st renderState) string {
 	blockType, _ := node.Block["type"].(string)
-	if blockType == "column_list" || blockType == "column" || blockType == "synced_block" {
-		if len(node.Children) > 0 {
-			c.renderBlocksToMarkdown(b, node.Children, indent)
-		}
-		return
+	st.number = seq.nextNumber()
+	md := c.renderBlockToMarkdown(node, st)
+	if md == "" {
+		return ""
 	}
-	if blockType == "table" {
-		c.renderTableToMarkdown(b, node, indent)
-		return
+	return seq.advance(c.listKind(blockType)) + md
+}
+
+// renderBlockToMarkdown renders a block and its children.
+func (c *NotionMarkdownConverter) renderBlockToMarkdown(node BlockNode, st renderState) string {
+	blockType, _ := node.Block["type"].(string)
+	switch {
+	case blockType == "column_list" && c.columns != ColumnsFlatten:
+		return c.renderColumnsToMarkdown(node, st)
+	case blockType == "column_list" || blockType == "column" || blockType == "synced_block":
+		return c.renderBlocksToMarkdown(node.Children, st)
+	case c.toggles == ToggleDetails && isToggle(node.Block):
+		return c.renderDetailsToMarkdown(node, st)
+	case blockType == "table":
+		return c.renderTableToMarkdown(node)
+	case blockType == "child_database" && node.database != nil:
+		return c.renderChildDatabaseToMarkdown(node, st)
+	case blockType == "child_page" && node.pageDepth > 0:
+		return c.renderInlinedPageToMarkdown(node, st)
 	}
-	line := c.blockToMarkdown(node.Block, indent)
-	if strings.TrimSpace(line) != "" {
-		b.WriteString(line)
+	text := c.blockToMarkdown(node, st)
+	childState := renderState{headingShift: st.headingShift}
+	if c.toggles == ToggleSections && isToggle(node.Block) && blockType != "toggle" {
+		childState.headingShift = headingLevel(blockType) + st.headingShift
 	}
+	children := ""
 	if len(node.Children) > 0 {
-		if strings.TrimSpace(line) != "" && !c.isListItem(blockType) {
-			b.WriteString("\n")
+		children = c.renderBlocksToMarkdown(node.Children, childState)
+	}
+	if kind := c.listKind(blockType); kind != "" {
+		marker := "- "
+		if kind == "numbered" {
+			marker = strconv.Itoa(st.number) + ". "
+		}
+		if text == "" {
+			if children == "" {
+				return ""
+			}
+			text = strings.TrimSpace(marker)
+		}
+		width := strings.Repeat(" ", len(marker))
+		text = indentLines(text, width, false)
+		if children == "" {
+			return text
 		}
-		childIndent := indent
-		if c.shouldIndentChildren(blockType) {
-			childIndent++
+		sep := "\n\n"
+		if c.listKind(firstBlockType(node.Children)) != "" {
+			sep = "\n"
 		}
-		c.renderBlocksToMarkdown(b, node.Children, childIndent)
+		return text + sep + indentLines(children, width, true)
+	}
+	if children == "" {
+		return text
+	}
+	if blockType == "quote" || blockType == "callout" {
+		children = indentLines(children, "> ", true)
+		if text == "" {
+			return children
+		}
+		return text + "\n>\n" + children
+	}
+	if text == "" {
+		return children
 	}
+	return text + "\n\n" + children
 }
 
-func (c *NotionMarkdownConverter) blockToMarkdown(block map[string]any, indent int) string {
-	blockType, _ := block["type"].(string)
-	pad := strings.Repeat("  ", indent)
-	switch blockType {
-	case "heading_1":
-		text := c.extractRichText(block[blockType])
-		if text == "" {
-			return ""
+// indentLines prefixes the lines of s, starting with the first line only when
+// first is set. Blank lines get the prefix without trailing spaces so they
+// don't carry stray whitespace.
+func indentLines(s, prefix string, first bool) string {
+	lines := strings.Split(s, "\n")
+	blank := strings.TrimRight(prefix, " ")
+	for i, line := range lines {
+		if i == 0 && !first {
+			continue
 		}
-		return pad + "# " + text
-	case "heading_2":
-		text := c.extractRichText(block[blockType])
-		if text == "" {
-			return ""
+		if line == "" {
+			lines[i] = blank
+		} else {
+			lines[i] = prefix + line
 		}
-		return pad + "## " + text
-	case "heading_3":
-		text := c.extractRichText(block[blockType])
+	}
+	return strings.Join(lines, "\n")
+}
+
+func firstBlockType(nodes []BlockNode) string {
+	if len(nodes) == 0 {
+		return ""
+	}
+	blockType, _ := nodes[0].Block["type"].(string)
+	return blockType
+}
+
+// renderInlinedPageToMarkdown renders an expanded child page as a heading
+// followed by its content, with the content's headings demoted below it.
+func (c *NotionMarkdownConverter) renderInlinedPageToMarkdown(node BlockNode, st renderState) string {
+	childPage, _ := node.Block["child_page"].(map[string]any)
+	title, _ := childPage["title"].(string)
+	if title == "" {
+		title = "Untitled"
+	}
+	level := st.headingShift + 2
+	heading := headingToMarkdown(level, title)
+	children := c.renderBlocksToMarkdown(node.Children, renderState{headingShift: level})
+	if children == "" {
+		return heading
+	}
+	return heading + "\n\n" + children
+}
+
+// headingToMarkdown renders a heading at the given level. Levels beyond the
+// six supported by Markdown fall back to bold text.
+func headingToMarkdown(level int, text string) string {
+	if level > 6 {
+		return "**" + text + "**"
+	}
+	return strings.Repeat("#", level) + " " + text
+}
+
+func (c *NotionMarkdownConverter) blockToMarkdown(node BlockNode, st renderState) string {
+	block := node.Block
+	blockType, _ := block["type"].(string)
+	switch blockType {
+	case "heading_1", "heading_2", "heading_3":
+		data, _ := block[blockType].(map[string]any)
+		text := c.extractRichText(data)
 		if text == "" {
 			return ""
 		}
-		return pad + "### " + text
+		level := headingLevel(blockType) + st.headingShift
+		return headingToMarkdown(level, c.colorize(escapeHeading(text), data))
 	case "paragraph":
-		text := c.extractRichText(block[blockType])
+		text := c.blockText(block[blockType])
 		if text == "" {
 			return ""
 		}
-		return pad + text
+		return text
 	case "bulleted_list_item":
-		text := c.extractRichText(block[blockType])
+		text := c.blockText(block[blockType])
 		if text == "" {
 			return ""
 		}
-		return pad + "- " + text
+		return "- " + text
 	case "numbered_list_item":
-		text := c.extractRichText(block[blockType])
+		text := c.blockText(block[blockType])
 		if text == "" {
 			return ""
 		}
-		var number int = 1
-		if numberedItem, ok := block[blockType].(map[string]any); ok {
-			if num, ok := numberedItem["number"].(int); ok {
-				number = num
-			}
-		}
-		return pad + strconv.Itoa(number) + ". " + text
+		return strconv.Itoa(st.number) + ". " + text
 	case "to_do":
 		todoBlock, _ := block[blockType].(map[string]any)
 		checked, _ := todoBlock["checked"].(bool)
-		text := c.extractRichText(todoBlock)
+		text := c.blockText(todoBlock)
 		checkbox := "[ ]"
 		if checked {
 			checkbox = "[x]"
 		}
-		return pad + "- " + checkbox + " " + text
+		if text == "" {
+			return "- " + checkbox
+		}
+		return "- " + checkbox + " " + text
 	case "quote":
-		text := c.extractRichText(block[blockType])
+		text := c.blockText(block[blockType])
 		if text == "" {
 			return ""
 		}
-		return pad + "> " + text
+		return indentLines(text, "> ", true)
 	case "callout":
 		calloutBlock, _ := block[blockType].(map[string]any)
-		text := c.extractRichText(calloutBlock)
+		text := c.blockText(calloutBlock)
 		if text == "" {
 			return ""
 		}
@@ -258,20 +662,22 @@ func (c *NotionMarkdownConverter) blockToMarkdown(block map[string]any, indent i
 				}
 			}
 		}
-		return pad + "> " + emoji + text
+		return indentLines(emoji+text, "> ", true)
 	case "code":
 		codeBlock, _ := block[blockType].(map[string]any)
-		text := c.extractRichText(codeBlock)
-		if text == "" {
+		richText, _ := codeBlock["rich_text"].([]any)
+		text := strings.Trim(rawRichText(richText), "\n")
+		if strings.TrimSpace(text) == "" {
 			return ""
 		}
 		language, _ := codeBlock["language"].(string)
 		if language == "" {
 			language = "plaintext"
 		}
-		return pad + "```" + language + "\n" + text + "\n" + pad + "```"
+		fence := codeFence(text)
+		return fence + language + "\n" + text + "\n" + fence
 	case "divider":
-		return pad + "---"
+		return "---"
 	case "bookmark", "embed", "link_preview":
 		var url string
 		if blockData, ok := block[blockType].(map[string]any); ok {
@@ -280,75 +686,99 @@ func (c *NotionMarkdownConverter) blockToMarkdown(block map[string]any, indent i
 		if url == "" {
 			return ""
 		}
-		return pad + "[" + blockType + "](" + url + ")"
+		return "[" + blockType + "](" + escapeLinkDestination(url) + ")"
 	case "link_to_page":
 		linkBlock, _ := block[blockType].(map[string]any)
 		linkType, _ := linkBlock["type"].(string)
-		if linkType == "page_id" {
+		switch linkType {
+		case "page_id":
 			if pageID, _ := linkBlock["page_id"].(string); pageID != "" {
-				url := "https://www.notion.so/" + pageID
-				return pad + "[link](" + url + ")"
+				return "[" + escapeInline(c.pageTitle(pageID, "")) + "](" + notionURL(pageID) + ")"
+			}
+		case "database_id":
+			if databaseID, _ := linkBlock["database_id"].(string); databaseID != "" {
+				return "[" + escapeInline(c.pageTitle(databaseID, "")) + "](" + notionURL(databaseID) + ")"
 			}
 		}
 		return ""
 	case "child_page":
 		childPage, _ := block[blockType].(map[string]any)
 		title, _ := childPage["title"].(string)
+		if c.childPages != ChildPageHeading {
+			id, _ := block["id"].(string)
+			if id == "" {
+				return ""
+			}
+			if title == "" {
+				title = "Untitled"
+			}
+			return "[" + escapeInline(title) + "](" + notionURL(id) + ") (page ID: " + id + ")"
+		}
+		if title == "" {
+			return ""
+		}
+		return headingToMarkdown(st.headingShift+2, escapeHeading(escapeInline(title)))
+	case "child_database":
+		childDatabase, _ := block[blockType].(map[string]any)
+		title, _ := childDatabase["title"].(string)
 		if title == "" {
 			return ""
 		}
-		return pad + "## " + title
+		return headingToMarkdown(st.headingShift+2, escapeHeading(escapeInline(title)))
 	case "image":
 		imageBlock, _ := block[blockType].(map[string]any)
-		var url string
-		imageType, _ := imageBlock["type"].(string)
-		if imageType == "external" {
-			if external, ok := imageBlock["external"].(map[string]any); ok {
-				url, _ = external["url"].(string)
-			}
-		} else if imageType == "file" {
-			if file, ok := imageBlock["file"].(map[string]any); ok {
-				url, _ = file["url"].(string)
-			}
+		url, _ := fileObjectURL(imageBlock)
+		if node.assetURL != "" {
+			url = node.assetURL
 		}
 		alt := "image"
 		if caption, ok := imageBlock["caption"].([]any); ok && len(caption) > 0 {
-			var captionText strings.Builder
-			for _, item := range caption {
-				if itemMap, ok := item.(map[string]any); ok {
-					if plainText, ok := itemMap["plain_text"].(string); ok {
-						captionText.WriteString(plainText)
-					}
-				}
-			}
-			if captionText.Len() > 0 {
-				alt = captionText.String()
+			if captionText := PlainText(caption); captionText != "" {
+				alt = escapeInline(captionText)
 			}
 		}
 		if url == "" {
 			return ""
 		}
-		return pad + "![" + alt + "](" + url + ")"
+		return "![" + alt + "](" + escapeLinkDestination(url) + ")"
+	case "video", "audio", "pdf", "file":
+		fileBlock, _ := block[blockType].(map[string]any)
+		url, _ := fileObjectURL(fileBlock)
+		if node.assetURL != "" {
+			url = node.assetURL
+		}
+		if url == "" {
+			return ""
+		}
+		name, _ := fileBlock["name"].(string)
+		if name == "" {
+			name = fileNameFromURL(url)
+		}
+		line := "[" + mediaLabels[blockType] + ": " + escapeInline(name) + "](" + escapeLinkDestination(url) + ")"
+		if caption := c.extractRichText(map[string]any{"rich_text": fileBlock["caption"]}); caption != "" {
+			line += " — " + caption
+		}
+		return line
 	case "toggle":
-		text := c.extractRichText(block[blockType])
+		text := c.blockText(block[blockType])
 		if text == "" {
 			return ""
 		}
-		return pad + "- " + text
+		return "- " + text
 	case "equation":
 		equationBlock, _ := block[blockType].(map[string]any)
 		expression, _ := equationBlock["expression"].(string)
 		if expression == "" {
 			return ""
 		}
-		return pad + "$$\n" + expression + "\n$$"
+		return "$$\n" + expression + "\n$$"
 	case "table":
 		return ""
 	default:
 		if blockData, ok := block[blockType]; ok {
-			text := c.extractRichText(blockData)
+			text := c.blockText(blockData)
 			if text != "" {
-				return pad + text
+				return text
 			}
 		}
 		return ""
@@ -375,7 +805,8 @@ func (c *NotionMarkdownConverter) extractRichText(blockData any) string {
 		if !ok {
 			continue
 		}
-		if itemType, _ := itemMap["type"].(string); itemType == "equation" {
+		itemType, _ := itemMap["type"].(string)
+		if itemType == "equation" {
 			if equation, ok := itemMap["equation"].(map[string]any); ok {
 				if expr, _ := equation["expression"].(string); expr != "" {
 					result.WriteString("$" + expr + "$")
@@ -384,20 +815,220 @@ func (c *NotionMarkdownConverter) extractRichText(blockData any) string {
 			continue
 		}
 		plainText, _ := itemMap["plain_text"].(string)
+		href, _ := itemMap["href"].(string)
+		if itemType == "mention" {
+			plainText, href = c.mentionToText(itemMap, plainText, href)
+		}
 		if plainText == "" {
 			continue
 		}
-		if annotations, ok := itemMap["annotations"].(map[string]any); ok {
-			plainText = c.applyAnnotations(plainText, annotations)
-		}
-		if href, ok := itemMap["href"].(string); ok && href != "" {
-			plainText = "[" + plainText + "](" + href + ")"
+		annotations, _ := itemMap["annotations"].(map[string]any)
+		plainText = c.applyAnnotations(plainText, annotations)
+		if href != "" {
+			plainText = "[" + plainText + "](" + escapeLinkDestination(href) + ")"
 		}
 		result.WriteString(plainText)
 	}
 	return strings.TrimSpace(result.String())
 }
 
+// rawRichText concatenates the plain_text of a rich text array without
+// trimming or escaping, for content such as code that is rendered verbatim.
+func rawRichText(richText []any) string {
+	var b strings.Builder
+	for _, item := range richText {
+		itemMap, _ := item.(map[string]any)
+		if pt, _ := itemMap["plain_text"].(string); pt != "" {
+			b.WriteString(pt)
+		}
+	}
+	return b.String()
+}
+
+// mentionToText returns the display text and link target for a mention rich
+// text item. Page and database mentions link to the referenced object, user
+// mentions render as @Name and date mentions show their full range.
+func (c *NotionMarkdownConverter) mentionToText(item map[string]any, plainText, href string) (string, string) {
+	mention, _ := item["mention"].(map[string]any)
+	mentionType, _ := mention["type"].(string)
+	data, _ := mention[mentionType].(map[string]any)
+	switch mentionType {
+	case "user":
+		name, _ := data["name"].(string)
+		if name == "" {
+			name = strings.TrimPrefix(plainText, "@")
+		}
+		if name == "" {
+			return "", ""
+		}
+		return "@" + name, ""
+	case "date":
+		start, _ := data["start"].(string)
+		if start == "" {
+			return plainText, href
+		}
+		end, _ := data["end"].(string)
+		tz, _ := data["time_zone"].(string)
+		return "@" + formatNotionDate(start, end, tz), ""
+	case "page":
+		id, _ := data["id"].(string)
+		if id == "" {
+			return plainText, href
+		}
+		return c.pageTitle(id, plainText), notionURL(id)
+	case "database":
+		id, _ := data["id"].(string)
+		if id == "" {
+			return plainText, href
+		}
+		if plainText == "" {
+			plainText = "Untitled"
+		}
+		return plainText, notionURL(id)
+	case "link_preview":
+		if url, _ := data["url"].(string); url != "" {
+			if plainText == "" {
+				plainText = url
+			}
+			return plainText, url
+		}
+	}
+	return plainText, href
+}
+
+// resolveLinkedTitles looks up the titles of pages and databases referenced
+// by page mentions and link_to_page blocks so they can be rendered as labelled
+// links. Lookups are cached for the lifetime of the converter; failures are
+// cached too and fall back to the mention's plain text.
+func (c *NotionMarkdownConverter) resolveLinkedTitles(ctx context.Context, blocks []map[string]any) {
+	for _, block := range blocks {
+		for _, ref := range linkedObjects(block) {
+			c.titlesMu.Lock()
+			_, seen := c.titles[ref.id]
+			c.titlesMu.Unlock()
+			if seen {
+				continue
+			}
+			title := ""
+			if ref.database {
+				if db, err := c.client.GetDatabase(ctx, ref.id); err == nil {
+					title = PlainText(db.Title)
+				}
+			} else if pg, err := c.client.GetPage(ctx, ref.id); err == nil {
+				title = ExtractNotionTitle(pg.Properties)
+			}
+			c.titlesMu.Lock()
+			c.titles[ref.id] = title
+			c.titlesMu.Unlock()
+		}
+	}
+}
+
+// pageTitle returns the cached title for pageID, falling back to fallback and
+// then to "Untitled" when the title is unknown.
+func (c *NotionMarkdownConverter) pageTitle(pageID, fallback string) string {
+	c.titlesMu.Lock()
+	title := c.titles[pageID]
+	c.titlesMu.Unlock()
+	if title != "" {
+		return title
+	}
+	if fallback != "" {
+		return fallback
+	}
+	return "Untitled"
+}
+
+type linkedObject struct {
+	id       string
+	database bool
+}
+
+// linkedObjects returns the pages and databases referenced from a block,
+// either by a link_to_page block or by page mentions in its rich text.
+func linkedObjects(block map[string]any) []linkedObject {
+	blockType, _ := block["type"].(string)
+	data, _ := block[blockType].(map[string]any)
+	var refs []linkedObject
+	if blockType == "link_to_page" {
+		if id, _ := data["page_id"].(string); id != "" {
+			refs = append(refs, linkedObject{id: id})
+		}
+		if id, _ := data["database_id"].(string); id != "" {
+			refs = append(refs, linkedObject{id: id, database: true})
+		}
+		return refs
+	}
+	var arrays [][]any
+	for _, key := range []string{"rich_text", "text", "caption"} {
+		if arr, ok := data[key].([]any); ok {
+			arrays = append(arrays, arr)
+		}
+	}
+	if cells, ok := data["cells"].([]any); ok {
+		for _, cell := range cells {
+			if arr, ok := cell.([]any); ok {
+				arrays = append(arrays, arr)
+			}
+		}
+	}
+	for _, arr := range arrays {
+		for _, item := range arr {
+			itemMap, _ := item.(map[string]any)
+			mention, _ := itemMap["mention"].(map[string]any)
+			if mt, _ := mention["type"].(string); mt != "page" {
+				continue
+			}
+			page, _ := mention["page"].(map[string]any)
+			if id, _ := page["id"].(string); id != "" {
+				refs = append(refs, linkedObject{id: id})
+			}
+		}
+	}
+	return refs
+}
+
+// notionURL returns the canonical notion.so URL for a page, database or block ID.
+func notionURL(id string) string {
+	return "https://www.notion.so/" + strings.ReplaceAll(id, "-", "")
+}
+
+// formatNotionDate renders a Notion date value. Date-times are shown as
+// "2006-01-02 15:04", ranges are joined with an arrow and the time zone, when
+// present, is appended in parentheses.
+func formatNotionDate(start, end, timeZone string) string {
+	out := formatNotionTime(start)
+	if end != "" {
+		out += " → " + formatNotionTime(end)
+	}
+	if timeZone != "" {
+		out += " (" + timeZone + ")"
+	}
+	return out
+}
+
+func formatNotionTime(s string) string {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05.000", "2006-01-02T15:04:05"} {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		out := t.Format("2006-01-02 15:04")
+		if layout == time.RFC3339 {
+			if _, offset := t.Zone(); offset == 0 {
+				out += " UTC"
+			} else {
+				out += " " + t.Format("-07:00")
+			}
+		}
+		return out
+	}
+	return s
+}
+
+// applyAnnotations escapes text for inline Markdown and wraps it in the markup
+// for its annotations. Text annotated as code becomes a code span instead of
+// being escaped.
 func (c *NotionMarkdownConverter) applyAnnotations(text string, annotations map[string]any) string {
 	if strings.TrimSpace(text) == "" {
 		return text
@@ -423,7 +1054,9 @@ func (c *NotionMarkdownConverter) applyAnnotations(text string, annotations map[
 		return leadingSpaces + trailingSpaces
 	}
 	if code, _ := annotations["code"].(bool); code {
-		text = "`" + text + "`"
+		text = codeSpan(text)
+	} else {
+		text = escapeInline(text)
 	}
 	if bold, _ := annotations["bold"].(bool); bold {
 		text = "**" + text + "**"
@@ -437,19 +1070,13 @@ func (c *NotionMarkdownConverter) applyAnnotations(text string, annotations map[
 	if underline, _ := annotations["underline"].(bool); underline {
 		text = "<u>" + text + "</u>"
 	}
+	if color, _ := annotations["color"].(string); color != "" {
+		text = c.colorizeText(text, color)
+	}
 	return leadingSpaces + text + trailingSpaces
 }
 
-func (c *NotionMarkdownConverter) isListItem(blockType string) bool {
-	return blockType == "bulleted_list_item" || blockType == "numbered_list_item" || blockType == "to_do"
-}
-
-func (c *NotionMarkdownConverter) shouldIndentChildren(blockType string) bool {
-	return blockType == "bulleted_list_item" || blockType == "numbered_list_item" || blockType == "to_do" || blockType == "quote" || blockType == "callout" || blockType == "toggle"
-}
-
-func (c *NotionMarkdownConverter) renderTableToMarkdown(b *strings.Builder, node BlockNode, indent int) {
-	pad := strings.Repeat("  ", indent)
+func (c *NotionMarkdownConverter) renderTableToMarkdown(node BlockNode) string {
 	hasColumnHeader := false
 	hasRowHeader := false
 	numCols := 0
@@ -475,7 +1102,7 @@ func (c *NotionMarkdownConverter) renderTableToMarkdown(b *strings.Builder, node
 		row := make([]string, 0, len(cellsAny))
 		for _, cellAny := range cellsAny {
 			if rtArr, ok := cellAny.([]any); ok {
-				cellText := c.extractRichText(map[string]any{"rich_text": rtArr})
+				cellText := escapeTableCell(c.extractRichText(map[string]any{"rich_text": rtArr}))
 				row = append(row, cellText)
 			} else {
 				row = append(row, "")
@@ -487,8 +1114,7 @@ func (c *NotionMarkdownConverter) renderTableToMarkdown(b *strings.Builder, node
 		}
 	}
 	if len(rows) == 0 || numCols == 0 {
-		b.WriteString(pad + "[Table]")
-		return
+		return "[Table]"
 	}
 	for i := range rows {
 		if len(rows[i]) < numCols {
@@ -509,6 +1135,13 @@ func (c *NotionMarkdownConverter) renderTableToMarkdown(b *strings.Builder, node
 			header[i] = ""
 		}
 	}
+	if c.wideTableColumns > 0 && numCols > c.wideTableColumns {
+		keys := header
+		if !hasColumnHeader {
+			keys = nil
+		}
+		return keyValueTable(keys, body, hasRowHeader)
+	}
 	if hasRowHeader {
 		for i := range body {
 			if len(body[i]) > 0 {
@@ -520,13 +1153,114 @@ func (c *NotionMarkdownConverter) renderTableToMarkdown(b *strings.Builder, node
 			}
 		}
 	}
-	b.WriteString(pad + "| " + strings.Join(header, " | ") + " |\n")
-	sepCells := make([]string, numCols)
-	for i := 0; i < numCols; i++ {
+	return c.markdownTable(header, body)
+}
+
+func (c *NotionMarkdownConverter) markdownTable(header []string, body [][]string) string {
+	if c.alignTables {
+		return alignedMarkdownTable(header, body, c.maxTableColumnWidth)
+	}
+	var b strings.Builder
+	b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	sepCells := make([]string, len(header))
+	for i := range sepCells {
 		sepCells[i] = "---"
 	}
-	b.WriteString(pad + "| " + strings.Join(sepCells, " | ") + " |")
+	b.WriteString("| " + strings.Join(sepCells, " | ") + " |")
 	for _, r := range body {
-		b.WriteString("\n" + pad + "| " + strings.Join(r, " | ") + " |")
+		b.WriteString("\n| " + strings.Join(r, " | ") + " |")
+	}
+	return b.String()
+}
+
+// queryChildDatabase fetches the schema and up to childDatabaseRows rows of a
+// child database. Errors are kept on the result so the rest of the page can
+// still be rendered.
+func (c *NotionMarkdownConverter) queryChildDatabase(ctx context.Context, databaseID string) *databaseTable {
+	db, err := c.client.GetDatabase(ctx, databaseID)
+	if err != nil {
+		return &databaseTable{linked: IsLinkedDatabaseError(err), err: err}
+	}
+	table := &databaseTable{columns: schemaPropertyNames(db.Properties)}
+	req := NotionDatabaseQueryRequest{PageSize: 100}
+	if c.childDatabaseRows < req.PageSize {
+		req.PageSize = c.childDatabaseRows
+	}
+	for {
+		resp, err := c.client.QueryDatabase(ctx, databaseID, req)
+		if err != nil {
+			// Keep the rows of the pages already fetched.
+			table.err = err
+			return table
+		}
+		for _, raw := range resp.Results {
+			if len(table.rows) >= c.childDatabaseRows {
+				table.truncated = true
+				return table
+			}
+			var pg NotionPage
+			if err := json.Unmarshal(raw, &pg); err != nil {
+				continue
+			}
+			row := make([]string, len(table.columns))
+			for i, name := range table.columns {
+				if prop, ok := pg.Properties[name].(map[string]any); ok {
+					row[i] = escapeTableCell(escapeInline(PropertyText(prop)))
+				}
+			}
+			table.rows = append(table.rows, row)
+		}
+		if !resp.HasMore || resp.NextCursor == "" {
+			return table
+		}
+		if len(table.rows) >= c.childDatabaseRows {
+			table.truncated = true
+			return table
+		}
+		req.StartCursor = resp.NextCursor
+	}
+}
+
+// IsLinkedDatabaseError reports whether err is how the API answers a request
+// for a linked database view. Such views appear as child_database blocks on
+// pages the integration can read, but retrieving them fails with a
+// validation error saying the database is linked, unlike a database that is
+// missing or not shared, which is not found.
+func IsLinkedDatabaseError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest &&
+		apiErr.Code == "validation_error" && strings.Contains(apiErr.Message, "linked database")
+}
+
+func (c *NotionMarkdownConverter) renderChildDatabaseToMarkdown(node BlockNode, st renderState) string {
+	var parts []string
+	if title := c.blockToMarkdown(node, st); title != "" {
+		parts = append(parts, title)
+	}
+	table := node.database
+	switch {
+	case table.linked:
+		parts = append(parts, "[Linked database view; its source database is not available through the Notion API]")
+	case table.err != nil && len(table.rows) == 0:
+		parts = append(parts, "[Database could not be loaded]")
+	case len(table.columns) == 0 || len(table.rows) == 0:
+		parts = append(parts, "[Empty database]")
+	default:
+		header := make([]string, len(table.columns))
+		for i, name := range table.columns {
+			header[i] = escapeTableCell(escapeInline(name))
+		}
+		if c.wideTableColumns > 0 && len(header) > c.wideTableColumns {
+			parts = append(parts, keyValueTable(header, table.rows, true))
+		} else {
+			parts = append(parts, c.markdownTable(header, table.rows))
+		}
+		switch {
+		case table.err != nil:
+			parts = append(parts, "_Showing the first "+strconv.Itoa(len(table.rows))+" rows; the rest could not be loaded._")
+		case table.truncated:
+			parts = append(parts, "_Showing the first "+strconv.Itoa(len(table.rows))+" rows; more rows are not shown._")
+		}
 	}
+	return strings.Join(parts, "\n\n")
 }
@@ -0,0 +1,355 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/openai/notion-go-agents/internal/notiontest"
+)
+
+func newTestClient(srv *notiontest.Server) *Client {
+	return NewClient("secret", "", WithHTTPClient(srv.HTTPClient()))
+}
+
+func mentionItem(mentionType string, data map[string]any, plainText string) map[string]any {
+	return map[string]any{
+		"type":        "mention",
+		"mention":     map[string]any{"type": mentionType, mentionType: data},
+		"plain_text":  plainText,
+		"annotations": map[string]any{},
+	}
+}
+
+func TestConvertPageToMarkdownMentions(t *testing.T) {
+	srv := notiontest.New(t)
+	srv.AddPage(notiontest.Page("target", "Team Roadmap"))
+	srv.SetChildren("root",
+		notiontest.Block("b1", "paragraph", map[string]any{"rich_text": []any{
+			notiontest.TextItem("See "),
+			mentionItem("page", map[string]any{"id": "target"}, "Untitled"),
+			notiontest.TextItem(" and "),
+			mentionItem("page", map[string]any{"id": "target"}, "Untitled"),
+		}}),
+		notiontest.Block("b2", "paragraph", map[string]any{"rich_text": []any{
+			notiontest.TextItem("Owner "),
+			mentionItem("user", map[string]any{"id": "u1", "name": "Ada"}, "@Ada"),
+			notiontest.TextItem(" due "),
+			mentionItem("date", map[string]any{"start": "2024-03-01", "end": "2024-03-05", "time_zone": "Europe/Berlin"}, "March 1, 2024"),
+		}}),
+		notiontest.Block("b3", "link_to_page", map[string]any{"type": "page_id", "page_id": "target"}),
+		notiontest.Block("b4", "paragraph", map[string]any{"rich_text": []any{
+			mentionItem("page", map[string]any{"id": "missing"}, "Old Page"),
+		}}),
+	)
+
+	conv := NewNotionMarkdownConverter(newTestClient(srv))
+	got, err := conv.ConvertPageToMarkdown(context.Background(), "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "See [Team Roadmap](https://www.notion.so/target) and [Team Roadmap](https://www.notion.so/target)\n\n" +
+		"Owner @Ada due @2024-03-01 → 2024-03-05 (Europe/Berlin)\n\n" +
+		"[Team Roadmap](https://www.notion.so/target)\n\n" +
+		"[Old Page](https://www.notion.so/missing)"
+	if got != want {
+		t.Fatalf("unexpected markdown:\n%s\nwant:\n%s", got, want)
+	}
+	if n := srv.Requests("GET /v1/pages/target"); n != 1 {
+		t.Fatalf("expected one title lookup, got %d", n)
+	}
+}
+
+func TestFormatNotionDate(t *testing.T) {
+	tests := []struct {
+		start, end, tz string
+		want           string
+	}{
+		{"2024-03-01", "", "", "2024-03-01"},
+		{"2024-03-01T09:30:00.000Z", "", "", "2024-03-01 09:30 UTC"},
+		{"2024-03-01T09:30:00.000+02:00", "2024-03-01T10:00:00.000+02:00", "", "2024-03-01 09:30 +02:00 → 2024-03-01 10:00 +02:00"},
+		{"2024-03-01T09:30:00.000", "", "America/New_York", "2024-03-01 09:30 (America/New_York)"},
+	}
+	for _, tt := range tests {
+		if got := formatNotionDate(tt.start, tt.end, tt.tz); got != tt.want {
+			t.Errorf("formatNotionDate(%q, %q, %q) = %q, want %q", tt.start, tt.end, tt.tz, got, tt.want)
+		}
+	}
+}
+
+func TestConvertPageToMarkdownChildDatabase(t *testing.T) {
+	srv := notiontest.New(t)
+	srv.AddDatabase(notiontest.Database("db1", "Tasks", map[string]any{
+		"Name":   map[string]any{"type": "title", "title": map[string]any{}},
+		"Status": map[string]any{"type": "select", "select": map[string]any{}},
+	}),
+		notiontest.Row("r1", map[string]any{"Name": notiontest.TitleProperty("Write docs"), "Status": notiontest.SelectProperty("Done")}),
+		notiontest.Row("r2", map[string]any{"Name": notiontest.TitleProperty("Ship"), "Status": notiontest.SelectProperty("Todo")}),
+		notiontest.Row("r3", map[string]any{"Name": notiontest.TitleProperty("Celebrate")}),
+	)
+	srv.SetChildren("root",
+		notiontest.Block("db1", "child_database", map[string]any{"title": "Tasks"}),
+	)
+
+	plain := NewNotionMarkdownConverter(newTestClient(srv))
+	got, err := plain.ConvertPageToMarkdown(context.Background(), "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "## Tasks" {
+		t.Fatalf("unexpected markdown without option: %q", got)
+	}
+
+	conv := NewNotionMarkdownConverter(newTestClient(srv), WithChildDatabases(2))
+	got, err = conv.ConvertPageToMarkdown(context.Background(), "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "## Tasks\n\n" +
+		"| Name | Status |\n" +
+		"| --- | --- |\n" +
+		"| Write docs | Done |\n" +
+		"| Ship | Todo |\n\n" +
+		"_Showing the first 2 rows; more rows are not shown._"
+	if got != want {
+		t.Fatalf("unexpected markdown:\n%s\nwant:\n%s", got, want)
+	}
+
+	// A linked view of a database elsewhere cannot be retrieved.
+	srv.SetChildren("linked",
+		notiontest.Block("view1", "child_database", map[string]any{"title": "My tasks"}),
+	)
+	srv.SetFailure("GET /v1/databases/view1", notiontest.Failure{Status: 400, Code: "validation_error", Message: "Database with ID view1 is a linked database."})
+	got, err = conv.ConvertPageToMarkdown(context.Background(), "linked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "## My tasks\n\n[Linked database view; its source database is not available through the Notion API]"
+	if got != want {
+		t.Fatalf("unexpected markdown for linked view:\n%s", got)
+	}
+
+	// A database that is not shared is not mistaken for a linked view.
+	srv.SetChildren("unshared",
+		notiontest.Block("gone", "child_database", map[string]any{"title": "Private"}),
+	)
+	if got, err = conv.ConvertPageToMarkdown(context.Background(), "unshared"); err != nil {
+		t.Fatal(err)
+	}
+	if got != "## Private\n\n[Database could not be loaded]" {
+		t.Fatalf("unexpected markdown for an unshared database:\n%s", got)
+	}
+
+	// Rows fetched before a query fails are kept.
+	var rows []map[string]any
+	for i := 0; i < 101; i++ {
+		rows = append(rows, notiontest.Row("big"+strconv.Itoa(i), map[string]any{"Name": notiontest.TitleProperty("Row")}))
+	}
+	srv.AddDatabase(notiontest.Database("big", "Log", map[string]any{
+		"Name": map[string]any{"type": "title", "title": map[string]any{}},
+	}), rows...)
+	srv.SetFailure("POST /v1/databases/big/query", notiontest.Failure{Status: 500, Code: "internal_server_error", After: 1})
+	srv.SetChildren("partial", notiontest.Block("big", "child_database", map[string]any{"title": "Log"}))
+	got, err = NewNotionMarkdownConverter(newTestClient(srv), WithChildDatabases(200)).ConvertPageToMarkdown(context.Background(), "partial")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(got, "| Row |\n\n_Showing the first 100 rows; the rest could not be loaded._") {
+		t.Fatalf("unexpected markdown for a partly loaded database:\n%s", got)
+	}
+}
+
+func TestConvertPageToMarkdownChildPages(t *testing.T) {
+	srv := notiontest.New(t)
+	srv.SetChildren("root",
+		notiontest.TextBlock("p1", "paragraph", "Index"),
+		notiontest.WithChildren(notiontest.Block("a", "child_page", map[string]any{"title": "Alpha"})),
+		notiontest.Block("c", "child_page", map[string]any{"title": "Gamma"}),
+	)
+	srv.SetChildren("a",
+		notiontest.TextBlock("h1", "heading_1", "Intro"),
+		notiontest.WithChildren(notiontest.Block("b", "child_page", map[string]any{"title": "Beta"})),
+	)
+	srv.SetChildren("b",
+		notiontest.TextBlock("h2", "heading_2", "Details"),
+		// A page reachable from itself must not be expanded twice.
+		notiontest.WithChildren(notiontest.Block("a", "child_page", map[string]any{"title": "Alpha"})),
+	)
+
+	conv := NewNotionMarkdownConverter(newTestClient(srv), WithChildPages(ChildPageInline), WithChildPageLimits(3, 2))
+	got, err := conv.ConvertPageToMarkdown(context.Background(), "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Index\n\n" +
+		"## Alpha\n\n" +
+		"### Intro\n\n" +
+		"#### Beta\n\n" +
+		"###### Details\n\n" +
+		"[Alpha](https://www.notion.so/a) (page ID: a)\n\n" +
+		"[Gamma](https://www.notion.so/c) (page ID: c)"
+	if got != want {
+		t.Fatalf("unexpected inline markdown:\n%s\nwant:\n%s", got, want)
+	}
+
+	links := NewNotionMarkdownConverter(newTestClient(srv), WithChildPages(ChildPageLink))
+	got, err = links.ConvertPageToMarkdown(context.Background(), "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "Index\n\n" +
+		"[Alpha](https://www.notion.so/a) (page ID: a)\n\n" +
+		"[Gamma](https://www.notion.so/c) (page ID: c)"
+	if got != want {
+		t.Fatalf("unexpected link markdown:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// firstWriteRecorder records how many children requests had been made when
+// the first byte of output arrived.
+type firstWriteRecorder struct {
+	buf      strings.Builder
+	srv      *notiontest.Server
+	requests int
+}
+
+func (r *firstWriteRecorder) Write(p []byte) (int, error) {
+	if r.buf.Len() == 0 {
+		r.requests = r.srv.Requests("GET /v1/blocks/root/children")
+	}
+	return r.buf.Write(p)
+}
+
+func TestWritePageMarkdown(t *testing.T) {
+	srv := notiontest.New(t)
+	blocks := []map[string]any{notiontest.TextBlock("h", "heading_1", "Log")}
+	for i := 0; i < 150; i++ {
+		id := strconv.Itoa(i)
+		blocks = append(blocks, notiontest.TextBlock("n"+id, "numbered_list_item", "entry "+id))
+	}
+	blocks = append(blocks, notiontest.WithChildren(notiontest.TextBlock("q", "quote", "Done")))
+	srv.SetChildren("root", blocks...)
+	srv.SetChildren("q", notiontest.TextBlock("qc", "paragraph", "nested"))
+	srv.SetChildren("empty")
+
+	conv := NewNotionMarkdownConverter(newTestClient(srv))
+	want, err := conv.ConvertPageToMarkdown(context.Background(), "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &firstWriteRecorder{srv: srv}
+	before := srv.Requests("GET /v1/blocks/root/children")
+	if err := conv.WritePageMarkdown(context.Background(), got, "root"); err != nil {
+		t.Fatal(err)
+	}
+	if got.buf.String() != want {
+		t.Fatalf("streamed markdown differs:\n%s\nwant:\n%s", got.buf.String(), want)
+	}
+	if got.requests-before != 1 {
+		t.Fatalf("expected output after the first page of children, got it after %d requests", got.requests-before)
+	}
+
+	var empty strings.Builder
+	if err := conv.WritePageMarkdown(context.Background(), &empty, "empty"); err != nil {
+		t.Fatal(err)
+	}
+	if empty.String() != "(no textual content)" {
+		t.Fatalf("unexpected empty page output: %q", empty.String())
+	}
+}
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata/golden")
+
+// goldenBlock is a block fixture with its children nested inline.
+type goldenBlock map[string]any
+
+func (g goldenBlock) node() BlockNode {
+	node := BlockNode{Block: map[string]any(g)}
+	children, _ := g["children"].([]any)
+	for _, child := range children {
+		m, _ := child.(map[string]any)
+		node.Children = append(node.Children, goldenBlock(m).node())
+	}
+	return node
+}
+
+func TestRenderGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "golden", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".json")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fixture struct {
+				Options map[string]string `json:"options"`
+				Blocks  []goldenBlock     `json:"blocks"`
+			}
+			if err := json.Unmarshal(data, &fixture); err != nil {
+				t.Fatal(err)
+			}
+			nodes := make([]BlockNode, 0, len(fixture.Blocks))
+			for _, b := range fixture.Blocks {
+				nodes = append(nodes, b.node())
+			}
+			got := NewNotionMarkdownConverter(nil, goldenOptions(t, fixture.Options)...).renderBlocksToMarkdown(nodes, renderState{}) + "\n"
+
+			golden := strings.TrimSuffix(input, ".json") + ".md"
+			if *updateGolden {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Fatalf("output does not match %s:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+// goldenOptions maps the "options" object of a golden fixture to converter
+// options.
+func goldenOptions(t *testing.T, opts map[string]string) []ConverterOption {
+	modes := map[string]map[string]ConverterOption{
+		"colors": {
+			"html":       WithColors(ColorHTML),
+			"attributes": WithColors(ColorAttributes),
+		},
+		"toggles": {
+			"details":  WithToggles(ToggleDetails),
+			"sections": WithToggles(ToggleSections),
+		},
+		"columns": {
+			"html":      WithColumns(ColumnsHTML),
+			"separated": WithColumns(ColumnsSeparated),
+		},
+		"tables": {
+			"aligned": WithAlignedTables(0),
+		},
+		"wide_tables": {
+			"3": WithWideTableFallback(3),
+		},
+	}
+	var out []ConverterOption
+	for name, value := range opts {
+		opt, ok := modes[name][value]
+		if !ok {
+			t.Fatalf("unknown golden option %s=%s", name, value)
+		}
+		out = append(out, opt)
+	}
+	return out
+}
@@ -0,0 +1,195 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/internal/notiontest"
+)
+
+func newServer(t *testing.T, opts ...Option) *Server {
+	srv := notiontest.New(t)
+	srv.AddPage(notiontest.Page("guide", "On-call Guide"))
+	srv.SetChildren("guide", notiontest.TextBlock("p1", "paragraph", "Page the lead."))
+	client := notion.NewClient("secret", "", notion.WithHTTPClient(srv.HTTPClient()))
+	return NewServer(client, opts...)
+}
+
+type response struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+func TestServeStdio(t *testing.T) {
+	s := newServer(t)
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get_page","arguments":{"page_id":"guide"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"get_page","arguments":{"page_id":"missing"}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"resources/list"}`,
+		`{"jsonrpc":"2.0","id":6,"method":"resources/read","params":{"uri":"notion://page/guide"}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"resources/read","params":{"uri":"notion://page/missing"}}`,
+		`{"jsonrpc":"2.0","id":8,"method":"nope"}`,
+		`not json`,
+	}, "\n")
+	var out bytes.Buffer
+	if err := s.ServeStdio(context.Background(), strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 9 {
+		t.Fatalf("expected 9 responses, got %d:\n%s", len(lines), out.String())
+	}
+	resps := make([]response, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &resps[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	contains := func(i int, want string) {
+		t.Helper()
+		if !strings.Contains(string(resps[i].Result), want) {
+			t.Fatalf("response %d: expected %s in %s", resps[i].ID, want, resps[i].Result)
+		}
+	}
+	contains(0, `"protocolVersion":"2025-03-26"`)
+	contains(1, `"name":"search_notion"`)
+	contains(1, `"inputSchema"`)
+	contains(2, `"isError":false`)
+	contains(2, `Page the lead.`)
+	contains(3, `"isError":true`)
+	contains(3, `not_found`)
+	contains(4, `"uri":"notion://page/guide"`)
+	contains(5, `"text":"Page the lead."`)
+	if resps[6].Error == nil || resps[6].Error.Code != codeResourceNotFound {
+		t.Fatalf("expected a resource not found error, got %+v", resps[6])
+	}
+	if resps[7].Error == nil || resps[7].Error.Code != codeMethodNotFound {
+		t.Fatalf("expected a method not found error, got %+v", resps[7])
+	}
+	if resps[8].Error == nil || resps[8].Error.Code != codeParseError {
+		t.Fatalf("expected a parse error, got %+v", resps[8])
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	ts := httptest.NewServer(newServer(t))
+	defer ts.Close()
+
+	post := func(session, body string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		if session != "" {
+			req.Header.Set(sessionHeader, session)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`)
+	session := resp.Header.Get(sessionHeader)
+	var init response
+	json.NewDecoder(resp.Body).Decode(&init)
+	resp.Body.Close()
+	if session == "" || !strings.Contains(string(init.Result), `"protocolVersion":"2025-06-18"`) {
+		t.Fatalf("unexpected initialize response: session=%q %s", session, init.Result)
+	}
+
+	resp = post(session, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for a notification, got %d", resp.StatusCode)
+	}
+
+	resp = post(session, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"search_notion","arguments":{"query":"on-call"}}}`)
+	var call response
+	json.NewDecoder(resp.Body).Decode(&call)
+	resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "application/json" || !strings.Contains(string(call.Result), `On-call Guide`) {
+		t.Fatalf("unexpected tool result: %s", call.Result)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL, nil)
+	req.Header.Set(sessionHeader, session)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("failed to end the session: %v", err)
+	}
+	resp = post(session, `{"jsonrpc":"2.0","id":3,"method":"ping"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for an ended session, got %d", resp.StatusCode)
+	}
+
+	if resp, err := http.Get(ts.URL); err != nil || resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected GET to be rejected: %v", err)
+	}
+}
+
+func TestServeHTTPOrigin(t *testing.T) {
+	ping := func(h http.Handler, origin string) int {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	local := newServer(t)
+	allowed := newServer(t, WithAllowedOrigins("https://app.example.com"))
+	tests := []struct {
+		h      http.Handler
+		origin string
+		want   int
+	}{
+		{local, "", http.StatusOK},
+		{local, "http://localhost:3000", http.StatusOK},
+		{local, "http://127.0.0.1", http.StatusOK},
+		{local, "https://evil.example", http.StatusForbidden},
+		{local, "null", http.StatusForbidden},
+		{allowed, "https://app.example.com", http.StatusOK},
+		{allowed, "http://localhost:3000", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if got := ping(tt.h, tt.origin); got != tt.want {
+			t.Errorf("origin %q: got %d, want %d", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestSessionLimits(t *testing.T) {
+	s := newServer(t)
+	s.maxSessions = 2
+	first, _ := s.newSession()
+	second, _ := s.newSession()
+	s.sessions[first] = time.Now().Add(-2 * time.Minute)
+	s.sessions[second] = time.Now().Add(-time.Minute)
+	s.hasSession(first)
+	third, err := s.newSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.hasSession(first) || s.hasSession(second) || !s.hasSession(third) {
+		t.Fatalf("expected the least recently used session to be dropped: %v", s.sessions)
+	}
+	s.sessions[first] = time.Now().Add(-2 * s.sessionTTL)
+	if s.hasSession(first) || len(s.sessions) != 1 {
+		t.Fatalf("expected the idle session to expire: %v", s.sessions)
+	}
+}
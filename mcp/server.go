@@ -0,0 +1,335 @@
+// Package mcp serves Notion to AI assistants over the Model Context Protocol.
+//
+// A Server exposes the tools of the tools package (search, page read,
+// database query and schema) and Notion pages as resources addressed by
+// notion://page/<id> URIs, rendered to Markdown. It speaks JSON-RPC 2.0 over
+// the stdio transport (ServeStdio) and the streamable HTTP transport
+// (ServeHTTP), answering each request with a single JSON response.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/tools"
+)
+
+// protocolVersions are the MCP revisions the server implements, newest
+// first.
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// pageURIPrefix starts the URI of a page resource.
+const pageURIPrefix = "notion://page/"
+
+// JSON-RPC error codes.
+const (
+	codeParseError       = -32700
+	codeInvalidRequest   = -32600
+	codeMethodNotFound   = -32601
+	codeInvalidParams    = -32602
+	codeInternalError    = -32603
+	codeResourceNotFound = -32002
+)
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithToolset sets the tools served, instead of tools.New(client).
+func WithToolset(ts *tools.Toolset) Option {
+	return func(s *Server) {
+		s.tools = ts
+	}
+}
+
+// WithConverterOptions sets the options used to render page resources.
+func WithConverterOptions(opts ...notion.ConverterOption) Option {
+	return func(s *Server) {
+		s.converterOptions = opts
+	}
+}
+
+// WithAllowedOrigins sets the browser origins, such as
+// "https://app.example.com", allowed to call the HTTP transport. Requests
+// with any other Origin header are rejected, which keeps web pages from
+// reaching a local server through DNS rebinding. By default only origins on
+// localhost, 127.0.0.1 and [::1] are allowed. Requests without an Origin
+// header, which browsers always send, are allowed either way.
+func WithAllowedOrigins(origins ...string) Option {
+	return func(s *Server) {
+		s.allowedOrigins = make(map[string]bool, len(origins))
+		for _, o := range origins {
+			s.allowedOrigins[strings.ToLower(strings.TrimSuffix(o, "/"))] = true
+		}
+	}
+}
+
+// WithServerInfo sets the name and version reported to clients. The
+// defaults are "notion-go-agents" and "dev".
+func WithServerInfo(name, version string) Option {
+	return func(s *Server) {
+		s.name, s.version = name, version
+	}
+}
+
+// Server is an MCP server backed by a Notion client. It is safe for
+// concurrent use.
+type Server struct {
+	client           *notion.Client
+	tools            *tools.Toolset
+	converterOptions []notion.ConverterOption
+	name             string
+	version          string
+
+	allowedOrigins map[string]bool
+
+	mu sync.Mutex
+	// sessions maps the HTTP transport's session IDs to when they were last
+	// used. Sessions idle for longer than sessionTTL are dropped, and the
+	// least recently used one when there would be more than maxSessions.
+	sessions    map[string]time.Time
+	maxSessions int
+	sessionTTL  time.Duration
+}
+
+// NewServer returns a Server for client.
+func NewServer(client *notion.Client, opts ...Option) *Server {
+	s := &Server{
+		client:      client,
+		name:        "notion-go-agents",
+		version:     "dev",
+		sessions:    make(map[string]time.Time),
+		maxSessions: 1000,
+		sessionTTL:  time.Hour,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.tools == nil {
+		s.tools = tools.New(client, tools.WithConverterOptions(s.converterOptions...))
+	}
+	return s
+}
+
+// message is a JSON-RPC request, notification or response.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// Handle processes one JSON-RPC message and returns the response to send,
+// or nil when the message is a notification or a response.
+func (s *Server) Handle(ctx context.Context, data []byte) []byte {
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return encode(message{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: "parse error: " + err.Error()}})
+	}
+	if msg.Method == "" {
+		// Responses to server requests are not expected; ignore them.
+		if msg.ID != nil {
+			return nil
+		}
+		return encode(message{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeInvalidRequest, Message: "missing method"}})
+	}
+	if msg.ID == nil {
+		// Notifications, such as notifications/initialized, need no answer.
+		return nil
+	}
+	result, err := s.dispatch(ctx, msg.Method, msg.Params)
+	resp := message{JSONRPC: "2.0", ID: msg.ID}
+	if err != nil {
+		var re *rpcError
+		if !errors.As(err, &re) {
+			re = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		resp.Error = re
+	} else {
+		resp.Result = result
+	}
+	return encode(resp)
+}
+
+func encode(msg message) []byte {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		data, _ = json.Marshal(message{JSONRPC: "2.0", ID: msg.ID, Error: &rpcError{Code: codeInternalError, Message: "failed to encode response"}})
+	}
+	return data
+}
+
+func (s *Server) dispatch(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "initialize":
+		return s.initialize(params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.listTools(), nil
+	case "tools/call":
+		return s.callTool(ctx, params)
+	case "resources/list":
+		return s.listResources(ctx, params)
+	case "resources/templates/list":
+		return map[string]any{"resourceTemplates": []any{map[string]any{
+			"uriTemplate": pageURIPrefix + "{id}",
+			"name":        "Notion page",
+			"description": "A Notion page rendered as Markdown.",
+			"mimeType":    "text/markdown",
+		}}}, nil
+	case "resources/read":
+		return s.readResource(ctx, params)
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", method)}
+}
+
+func decodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: "invalid params: " + err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) initialize(params json.RawMessage) (any, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	// Answer with the requested revision when supported, else the newest.
+	version := protocolVersions[0]
+	for _, v := range protocolVersions {
+		if v == p.ProtocolVersion {
+			version = v
+		}
+	}
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities": map[string]any{
+			"tools":     map[string]any{},
+			"resources": map[string]any{},
+		},
+		"serverInfo":   map[string]any{"name": s.name, "version": s.version},
+		"instructions": "Search the Notion workspace with search_notion, then read pages with get_page or as notion://page/<id> resources.",
+	}, nil
+}
+
+func (s *Server) listTools() any {
+	var list []map[string]any
+	for _, t := range s.tools.Tools() {
+		list = append(list, map[string]any{
+			"name":        t.Name,
+			"description": t.Description,
+			"inputSchema": t.Parameters,
+		})
+	}
+	return map[string]any{"tools": list}
+}
+
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	out, err := s.tools.Call(ctx, p.Name, p.Arguments)
+	var te *tools.Error
+	if errors.As(err, &te) && te.Code == tools.CodeUnknownTool {
+		return nil, &rpcError{Code: codeInvalidParams, Message: te.Message}
+	}
+	// Tool failures are results the model can see and act on, not protocol
+	// errors.
+	return map[string]any{
+		"content": []any{map[string]any{"type": "text", "text": out}},
+		"isError": err != nil,
+	}, nil
+}
+
+// listResources lists the pages shared with the integration, a page of
+// search results at a time.
+func (s *Server) listResources(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Cursor string `json:"cursor"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	sr, err := s.client.Search(ctx, notion.NotionSearchRequest{
+		Filter:      &notion.NotionObjFilter{Property: "object", Value: "page"},
+		StartCursor: p.Cursor,
+		PageSize:    100,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resources := []any{}
+	for _, raw := range sr.Results {
+		var pg notion.NotionPage
+		if err := json.Unmarshal(raw, &pg); err != nil || pg.Object != "page" {
+			continue
+		}
+		title := notion.ExtractNotionTitle(pg.Properties)
+		if title == "" {
+			title = "Untitled"
+		}
+		resources = append(resources, map[string]any{
+			"uri":      pageURIPrefix + pg.ID,
+			"name":     title,
+			"mimeType": "text/markdown",
+		})
+	}
+	out := map[string]any{"resources": resources}
+	if sr.HasMore && sr.NextCursor != "" {
+		out["nextCursor"] = sr.NextCursor
+	}
+	return out, nil
+}
+
+func (s *Server) readResource(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	id, ok := strings.CutPrefix(p.URI, pageURIPrefix)
+	if !ok || id == "" || strings.ContainsAny(id, "/?#") {
+		return nil, &rpcError{Code: codeResourceNotFound, Message: fmt.Sprintf("unknown resource %q", p.URI)}
+	}
+	conv := notion.NewNotionMarkdownConverter(s.client, s.converterOptions...)
+	var b strings.Builder
+	if err := conv.WritePageMarkdown(ctx, &b, id); err != nil {
+		if notion.IsNotFound(err) {
+			return nil, &rpcError{Code: codeResourceNotFound, Message: fmt.Sprintf("page %s not found", id)}
+		}
+		return nil, err
+	}
+	return map[string]any{"contents": []any{map[string]any{
+		"uri":      p.URI,
+		"mimeType": "text/markdown",
+		"text":     b.String(),
+	}}}, nil
+}
@@ -0,0 +1,165 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxMessageSize bounds a single JSON-RPC message.
+const maxMessageSize = 4 << 20
+
+// ServeStdio reads newline-delimited JSON-RPC messages from r and writes the
+// responses to w, one per line, until r is exhausted or ctx is done.
+func (s *Server) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		resp := s.Handle(ctx, line)
+		if resp == nil {
+			continue
+		}
+		if _, err := w.Write(append(resp, '\n')); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read message: %w", err)
+	}
+	return nil
+}
+
+// sessionHeader carries the session ID of the streamable HTTP transport.
+const sessionHeader = "Mcp-Session-Id"
+
+// ServeHTTP implements the streamable HTTP transport. Clients POST one
+// JSON-RPC message per request and get the response as application/json;
+// the server never opens an event stream, so GET is not allowed. The
+// response to initialize carries a session ID, which later requests may
+// send back and DELETE ends. Requests from browser origins that are not
+// allowed (see WithAllowedOrigins) are rejected.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); origin != "" && !s.originAllowed(origin) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	session := r.Header.Get(sessionHeader)
+	if session != "" && !s.hasSession(session) {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+	case http.MethodDelete:
+		if session == "" {
+			http.Error(w, "missing session", http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		delete(s.sessions, session)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+		return
+	default:
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, "failed to read request", http.StatusBadRequest)
+		return
+	}
+	var probe struct {
+		Method string `json:"method"`
+	}
+	_ = json.Unmarshal(body, &probe)
+	resp := s.Handle(r.Context(), body)
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	if probe.Method == "initialize" && session == "" {
+		id, err := s.newSession()
+		if err != nil {
+			http.Error(w, "failed to start session", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set(sessionHeader, id)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// originAllowed reports whether a browser origin may use the transport.
+func (s *Server) originAllowed(origin string) bool {
+	if s.allowedOrigins != nil {
+		return s.allowedOrigins["*"] || s.allowedOrigins[strings.ToLower(strings.TrimSuffix(origin, "/"))]
+	}
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+// hasSession reports whether a session is live, marking it as used.
+func (s *Server) hasSession(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	used, ok := s.sessions[id]
+	if !ok || now.Sub(used) > s.sessionTTL {
+		delete(s.sessions, id)
+		return false
+	}
+	s.sessions[id] = now
+	return true
+}
+
+// newSession starts a session, first dropping idle sessions and, when the
+// limit is reached, the least recently used one.
+func (s *Server) newSession() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	id := hex.EncodeToString(b)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	oldest := ""
+	for sid, used := range s.sessions {
+		if now.Sub(used) > s.sessionTTL {
+			delete(s.sessions, sid)
+		} else if oldest == "" || used.Before(s.sessions[oldest]) {
+			oldest = sid
+		}
+	}
+	if len(s.sessions) >= s.maxSessions && oldest != "" {
+		delete(s.sessions, oldest)
+	}
+	s.sessions[id] = now
+	return id, nil
+}
@@ -0,0 +1,298 @@
+package notionexport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/chunk"
+)
+
+// JSONLRecord is one line of a JSON Lines corpus: a whole page, or a chunk of
+// one when the exporter has a chunker.
+type JSONLRecord struct {
+	// ID is the page ID, or "<page ID>#<chunk index>" for a chunk.
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	// Path is the breadcrumb of the page: the titles of its ancestor pages
+	// and databases, outermost first, followed by its own title.
+	Path []string `json:"path"`
+	// Properties holds the page's non-title properties converted with
+	// notion.PropertyValue.
+	Properties     map[string]any `json:"properties,omitempty"`
+	LastEditedTime string         `json:"last_edited_time"`
+	Text           string         `json:"text"`
+	// PageID and BlockIDs are set for chunks: the page the chunk belongs to
+	// and the blocks it was rendered from.
+	PageID   string   `json:"page_id,omitempty"`
+	BlockIDs []string `json:"block_ids,omitempty"`
+}
+
+// JSONLOption configures a JSONLExporter.
+type JSONLOption func(*JSONLExporter)
+
+// WithParent limits the export to pages below the page or database with the
+// given ID or URL, at any depth. If id holds no Notion ID, writing fails.
+func WithParent(id string) JSONLOption {
+	return func(e *JSONLExporter) {
+		parsed, err := notion.ParseID(id)
+		if err != nil {
+			e.err = fmt.Errorf("invalid parent: %w", err)
+			return
+		}
+		e.parentID = notion.NormalizeID(parsed)
+	}
+}
+
+// WithEditedSince limits the export to pages last edited at or after t.
+func WithEditedSince(t time.Time) JSONLOption {
+	return func(e *JSONLExporter) {
+		e.since = t
+	}
+}
+
+// WithConverterOptions sets the options used to render each page's text.
+func WithConverterOptions(opts ...notion.ConverterOption) JSONLOption {
+	return func(e *JSONLExporter) {
+		e.converterOptions = opts
+	}
+}
+
+// WithChunker writes one line per chunk produced by ch instead of one line
+// per page. A chunk's path continues with the headings it is under.
+func WithChunker(ch *chunk.Chunker) JSONLOption {
+	return func(e *JSONLExporter) {
+		e.chunker = ch
+	}
+}
+
+// JSONLExporter writes pages as JSON Lines, one JSONLRecord per line, while
+// the results of a search or database query are paged in.
+type JSONLExporter struct {
+	client           *notion.Client
+	enc              *json.Encoder
+	parentID         string
+	since            time.Time
+	converterOptions []notion.ConverterOption
+	chunker          *chunk.Chunker
+	// err is an invalid option, reported by every write.
+	err error
+
+	// ancestors caches the title and parent of the pages, databases and
+	// blocks looked up to build breadcrumbs.
+	ancestors map[string]ancestor
+}
+
+type ancestor struct {
+	title  string
+	parent map[string]any
+}
+
+// NewJSONLExporter returns an exporter that writes to w.
+func NewJSONLExporter(client *notion.Client, w io.Writer, opts ...JSONLOption) *JSONLExporter {
+	e := &JSONLExporter{
+		client:    client,
+		enc:       json.NewEncoder(w),
+		ancestors: make(map[string]ancestor),
+	}
+	e.enc.SetEscapeHTML(false)
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// WriteSearch writes the pages found by a workspace search, as returned by
+// notion.WalkWorkspace, and returns the number of lines written. With
+// WithEditedSince and no sort in req, results are requested newest first so
+// the search stops at the first older page.
+func (e *JSONLExporter) WriteSearch(ctx context.Context, req notion.NotionSearchRequest) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	sorted := false
+	if !e.since.IsZero() && req.Sort == nil {
+		req.Sort = &notion.NotionSort{Direction: "descending", Timestamp: "last_edited_time"}
+		sorted = true
+	}
+	n := 0
+	err := notion.WalkWorkspace(ctx, e.client, req, func(pg *notion.NotionPage) error {
+		if e.older(pg) {
+			if sorted {
+				return notion.ErrStopWalk
+			}
+			return nil
+		}
+		lines, err := e.write(ctx, pg)
+		n += lines
+		return err
+	})
+	return n, err
+}
+
+// WriteDatabase writes the rows of a database query, as returned by
+// notion.WalkNotionDatabase, and returns the number of lines written. With
+// WithEditedSince and no filter in req, the query filters on edit time.
+func (e *JSONLExporter) WriteDatabase(ctx context.Context, databaseID string, req notion.NotionDatabaseQueryRequest) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	if !e.since.IsZero() && req.Filter == nil {
+		req.Filter = map[string]any{
+			"timestamp":        "last_edited_time",
+			"last_edited_time": map[string]any{"on_or_after": e.since.UTC().Format(time.RFC3339)},
+		}
+	}
+	n := 0
+	err := notion.WalkNotionDatabase(ctx, e.client, databaseID, req, func(pg *notion.NotionPage) error {
+		if e.older(pg) {
+			return nil
+		}
+		lines, err := e.write(ctx, pg)
+		n += lines
+		return err
+	})
+	return n, err
+}
+
+// older reports whether pg was last edited before the WithEditedSince time.
+func (e *JSONLExporter) older(pg *notion.NotionPage) bool {
+	if e.since.IsZero() {
+		return false
+	}
+	t, err := time.Parse(time.RFC3339, pg.LastEditedTime)
+	return err == nil && t.Before(e.since)
+}
+
+// write converts pg and writes its lines unless it is outside the
+// WithParent page, and returns the number of lines written.
+func (e *JSONLExporter) write(ctx context.Context, pg *notion.NotionPage) (int, error) {
+	path, ids := e.breadcrumb(ctx, pg.Parent)
+	if e.parentID != "" && !contains(ids, e.parentID) {
+		return 0, nil
+	}
+	rec := JSONLRecord{
+		ID:             pg.ID,
+		Title:          notion.ExtractNotionTitle(pg.Properties),
+		URL:            pg.PublicURL,
+		LastEditedTime: pg.LastEditedTime,
+	}
+	if rec.URL == "" {
+		rec.URL = pg.URL
+	}
+	for name, v := range pg.Properties {
+		prop, _ := v.(map[string]any)
+		if t, _ := prop["type"].(string); t == "title" {
+			continue
+		}
+		if rec.Properties == nil {
+			rec.Properties = make(map[string]any)
+		}
+		rec.Properties[name] = notion.PropertyValue(prop)
+	}
+
+	if e.chunker == nil {
+		pc, err := notion.ConvertPage(ctx, e.client, pg, e.converterOptions...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to convert page %s: %w", pg.ID, err)
+		}
+		rec.Path = append(path, rec.Title)
+		rec.Text = pc.Markdown
+		return 1, e.encode(rec)
+	}
+	conv := notion.NewNotionMarkdownConverter(e.client, e.converterOptions...)
+	nodes, err := conv.GetBlockTree(ctx, pg.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get blocks of page %s: %w", pg.ID, err)
+	}
+	chunks := e.chunker.Split(conv, pg.ID, rec.Title, nodes)
+	for i, ch := range chunks {
+		line := rec
+		line.ID = fmt.Sprintf("%s#%d", pg.ID, ch.Index)
+		line.PageID = pg.ID
+		line.Path = append(append([]string{}, path...), ch.Breadcrumb...)
+		line.Text = ch.Text
+		line.BlockIDs = ch.BlockIDs
+		if err := e.encode(line); err != nil {
+			return i, err
+		}
+	}
+	return len(chunks), nil
+}
+
+func (e *JSONLExporter) encode(rec JSONLRecord) error {
+	if err := e.enc.Encode(rec); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	return nil
+}
+
+// breadcrumb walks up from a parent object and returns the titles of the
+// ancestor pages and databases, outermost first, and the normalized IDs of
+// every ancestor including blocks. The walk stops at the workspace or at the
+// first ancestor that cannot be retrieved.
+func (e *JSONLExporter) breadcrumb(ctx context.Context, parent map[string]any) ([]string, []string) {
+	var titles, ids []string
+	for depth := 0; depth < 32; depth++ {
+		parentType, _ := parent["type"].(string)
+		id, _ := parent[parentType].(string)
+		if id == "" {
+			break
+		}
+		a, err := e.lookup(ctx, parentType, id)
+		if err != nil {
+			break
+		}
+		if parentType != "block_id" {
+			titles = append([]string{a.title}, titles...)
+		}
+		ids = append(ids, notion.NormalizeID(id))
+		parent = a.parent
+	}
+	return titles, ids
+}
+
+func (e *JSONLExporter) lookup(ctx context.Context, parentType, id string) (ancestor, error) {
+	key := notion.NormalizeID(id)
+	if a, ok := e.ancestors[key]; ok {
+		return a, nil
+	}
+	var a ancestor
+	switch parentType {
+	case "page_id":
+		pg, err := e.client.GetPage(ctx, id)
+		if err != nil {
+			return a, err
+		}
+		a = ancestor{title: notion.ExtractNotionTitle(pg.Properties), parent: pg.Parent}
+	case "database_id":
+		db, err := e.client.GetDatabase(ctx, id)
+		if err != nil {
+			return a, err
+		}
+		a = ancestor{title: notion.PlainText(db.Title), parent: db.Parent}
+	case "block_id":
+		block, err := e.client.GetBlock(ctx, id)
+		if err != nil {
+			return a, err
+		}
+		a.parent, _ = block["parent"].(map[string]any)
+	default:
+		return a, fmt.Errorf("unsupported parent type %q", parentType)
+	}
+	e.ancestors[key] = a
+	return a, nil
+}
+
+func contains(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package notionexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/chunk"
+	"github.com/openai/notion-go-agents/internal/notiontest"
+)
+
+func decodeLines(t *testing.T, s string) []JSONLRecord {
+	t.Helper()
+	var out []JSONLRecord
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec JSONLRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+const handbookID = "55555555555555555555555555555555"
+
+func TestJSONLExporter(t *testing.T) {
+	srv := notiontest.New(t)
+	srv.AddPage(notiontest.Page(handbookID, "Handbook"))
+	ops := notiontest.Page("ops", "Ops")
+	ops["parent"] = map[string]any{"type": "page_id", "page_id": handbookID}
+	srv.AddPage(ops)
+	// A page inside a column block of Ops.
+	deep := notiontest.Page("deep", "Paging")
+	deep["parent"] = map[string]any{"type": "block_id", "block_id": "col"}
+	deep["last_edited_time"] = "2024-03-01T00:00:00.000Z"
+	srv.AddPage(deep)
+	srv.SetChildren("ops", notiontest.WithChildren(notiontest.Block("col", "column", map[string]any{})))
+	srv.SetChildren("col", notiontest.Block("deep", "child_page", map[string]any{"title": "Paging"}))
+	srv.SetChildren("deep", notiontest.TextBlock("p1", "paragraph", "Call the on-call."))
+	srv.AddPage(notiontest.Page("other", "Elsewhere"))
+	srv.AddDatabase(notiontest.Database("db", "Tasks", nil),
+		notiontest.Row("r1", map[string]any{
+			"Name":   notiontest.TitleProperty("Write docs"),
+			"Status": notiontest.SelectProperty("Done"),
+		}),
+	)
+
+	ctx := context.Background()
+	client := notion.NewClient("secret", "", notion.WithHTTPClient(srv.HTTPClient()))
+	var buf bytes.Buffer
+	n, err := NewJSONLExporter(client, &buf, WithParent("https://www.notion.so/acme/Handbook-"+handbookID)).WriteSearch(ctx, notion.NotionSearchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recs := decodeLines(t, buf.String())
+	if n != 2 || len(recs) != 2 {
+		t.Fatalf("expected the two pages below root, got %d: %+v", n, recs)
+	}
+	if got := recs[0]; got.ID != "deep" || strings.Join(got.Path, " > ") != "Handbook > Ops > Paging" ||
+		got.Text != "Call the on-call." || got.LastEditedTime != "2024-03-01T00:00:00.000Z" || got.URL != "https://www.notion.so/deep" {
+		t.Fatalf("unexpected record: %+v", got)
+	}
+	if got := recs[1]; got.ID != "ops" || strings.Join(got.Path, " > ") != "Handbook > Ops" {
+		t.Fatalf("unexpected record: %+v", got)
+	}
+
+	if _, err := NewJSONLExporter(client, &buf, WithParent("handbook")).WriteSearch(ctx, notion.NotionSearchRequest{}); !errors.Is(err, notion.ErrInvalidID) {
+		t.Fatalf("expected an invalid parent to be reported, got %v", err)
+	}
+
+	buf.Reset()
+	since := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	if n, err = NewJSONLExporter(client, &buf, WithEditedSince(since)).WriteSearch(ctx, notion.NotionSearchRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if recs = decodeLines(t, buf.String()); n != 1 || recs[0].ID != "deep" {
+		t.Fatalf("expected only the recently edited page, got %+v", recs)
+	}
+	if pages := srv.Requests("GET /v1/blocks/other/children"); pages != 0 {
+		t.Fatalf("expected older pages not to be converted, got %d fetches", pages)
+	}
+
+	buf.Reset()
+	if _, err = NewJSONLExporter(client, &buf).WriteDatabase(ctx, "db", notion.NotionDatabaseQueryRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	recs = decodeLines(t, buf.String())
+	if len(recs) != 1 || strings.Join(recs[0].Path, " > ") != "Tasks > Write docs" || recs[0].Properties["Status"] != "Done" {
+		t.Fatalf("unexpected database records: %+v", recs)
+	}
+}
+
+func TestJSONLExporterChunks(t *testing.T) {
+	srv := notiontest.New(t)
+	srv.AddPage(notiontest.Page("root", "Runbook"))
+	srv.SetChildren("root",
+		notiontest.TextBlock("p0", "paragraph", "Overview"),
+		notiontest.TextBlock("h1", "heading_2", "Escalation"),
+		notiontest.TextBlock("p1", "paragraph", "Page the lead."),
+	)
+	client := notion.NewClient("secret", "", notion.WithHTTPClient(srv.HTTPClient()))
+	var buf bytes.Buffer
+	n, err := NewJSONLExporter(client, &buf, WithChunker(chunk.New())).WriteSearch(context.Background(), notion.NotionSearchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recs := decodeLines(t, buf.String())
+	if n != 2 || len(recs) != 2 {
+		t.Fatalf("expected two chunk lines, got %+v", recs)
+	}
+	last := recs[1]
+	if last.ID != "root#1" || last.PageID != "root" || strings.Join(last.Path, " > ") != "Runbook > Escalation" ||
+		strings.Join(last.BlockIDs, ",") != "h1,p1" || last.Text != "## Escalation\n\nPage the lead." {
+		t.Fatalf("unexpected chunk line: %+v", last)
+	}
+}
@@ -0,0 +1,371 @@
+// Package notionexport writes Notion pages to files for backups, static site
+// generators and ingestion pipelines: as a Markdown directory tree built from
+// a notionsync store, or as a JSON Lines corpus streamed from search and
+// database results.
+package notionexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/internal/atomicfile"
+	"github.com/openai/notion-go-agents/notionsync"
+)
+
+const (
+	// storeDir is the directory below an export that holds its sync store.
+	storeDir = ".notion"
+	// manifestName is the file below an export that lists the files it wrote.
+	manifestName = ".notion-export.json"
+)
+
+// Result summarizes the files touched by an export.
+type Result struct {
+	Written   int
+	Unchanged int
+	Removed   int
+}
+
+// ExportWorkspace syncs the pages and databases below roots, given as IDs or
+// URLs, or the whole shared workspace when roots is empty, into a store kept
+// in dir/.notion and writes them below dir with ExportMarkdown. Both steps
+// only redo the work for what changed, so rerunning an interrupted export
+// resumes it.
+//
+// Child pages are rendered as links, which the export rewrites to point at
+// the exported files; pass notionsync.WithConverterOptions to change how
+// pages are rendered.
+func ExportWorkspace(ctx context.Context, client *notion.Client, dir string, roots []string, opts ...notionsync.Option) (*Result, error) {
+	if len(roots) == 0 {
+		var err error
+		if roots, err = notionsync.WorkspaceRoots(ctx, client); err != nil {
+			return nil, err
+		}
+	} else {
+		ids := make([]string, len(roots))
+		for i, root := range roots {
+			id, err := notion.ParseID(root)
+			if err != nil {
+				return nil, err
+			}
+			ids[i] = id
+		}
+		roots = ids
+	}
+	store := notionsync.NewDirStore(filepath.Join(dir, storeDir))
+	opts = append([]notionsync.Option{notionsync.WithConverterOptions(notion.WithChildPages(notion.ChildPageLink))}, opts...)
+	if _, err := notionsync.New(client, store, roots, opts...).Sync(ctx); err != nil {
+		return nil, fmt.Errorf("failed to sync workspace: %w", err)
+	}
+	return ExportMarkdown(store, dir)
+}
+
+// ExportMarkdown writes one Markdown file with YAML front matter for every
+// page and database in store. Files are laid out like the Notion hierarchy:
+// a page is written to "<slug>.md" and its children to the "<slug>"
+// directory next to it. A database is written as an index page listing its
+// rows, with the row pages in its directory. Links between exported pages
+// are rewritten to relative file paths.
+//
+// Exporting is idempotent: files whose content is unchanged are not
+// rewritten, and files from earlier exports of pages that were removed or
+// moved are deleted. Other files in dir are left alone.
+func ExportMarkdown(store notionsync.Store, dir string) (*Result, error) {
+	recs, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list records: %w", err)
+	}
+	paths := layout(recs)
+
+	previous, err := readManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		current[p] = true
+	}
+	// Record every file this export may write before writing any, so an
+	// interrupted export still cleans up after itself on the next run.
+	if err := writeManifest(dir, previous, current); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*notionsync.Record, len(recs))
+	for _, rec := range recs {
+		byID[notion.NormalizeID(rec.ID)] = rec
+	}
+	res := &Result{}
+	for _, rec := range recs {
+		id := notion.NormalizeID(rec.ID)
+		if paths[id] == "" {
+			continue
+		}
+		var content string
+		if rec.Kind == notionsync.KindDatabase {
+			content = databaseFile(rec, byID, paths)
+		} else {
+			content = pageFile(rec, paths)
+		}
+		written, err := writeIfChanged(filepath.Join(dir, filepath.FromSlash(paths[id])), content)
+		if err != nil {
+			return nil, err
+		}
+		if written {
+			res.Written++
+		} else {
+			res.Unchanged++
+		}
+	}
+
+	for p := range previous {
+		if current[p] {
+			continue
+		}
+		full := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.Remove(full); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to remove %s: %w", p, err)
+		}
+		res.Removed++
+		removeEmptyDirs(dir, filepath.Dir(full))
+	}
+	if err := writeManifest(dir, current); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// layout assigns each record a slash-separated file path. Records whose
+// parent is not in the store are placed at the top level.
+func layout(recs []*notionsync.Record) map[string]string {
+	byID := make(map[string]*notionsync.Record, len(recs))
+	for _, rec := range recs {
+		byID[notion.NormalizeID(rec.ID)] = rec
+	}
+	children := make(map[string][]*notionsync.Record)
+	for _, rec := range recs {
+		parent := notion.NormalizeID(rec.ParentID)
+		if byID[parent] == nil {
+			parent = ""
+		}
+		children[parent] = append(children[parent], rec)
+	}
+	// Order children as their parent lists them; the rest keep ID order.
+	for parent, kids := range children {
+		order := make(map[string]int)
+		if p := byID[parent]; p != nil {
+			for i, id := range p.Children {
+				order[notion.NormalizeID(id)] = i + 1
+			}
+		}
+		sort.SliceStable(kids, func(i, j int) bool {
+			a, b := order[notion.NormalizeID(kids[i].ID)], order[notion.NormalizeID(kids[j].ID)]
+			return a != 0 && (b == 0 || a < b)
+		})
+	}
+
+	paths := make(map[string]string, len(recs))
+	var assign func(dir, parent string)
+	assign = func(dir, parent string) {
+		kids := children[parent]
+		count := make(map[string]int)
+		for _, rec := range kids {
+			count[slugify(rec.Title)]++
+		}
+		for _, rec := range kids {
+			id := notion.NormalizeID(rec.ID)
+			if _, done := paths[id]; done {
+				continue
+			}
+			name := slugify(rec.Title)
+			if count[name] > 1 {
+				name += "-" + id[:min(8, len(id))]
+			}
+			paths[id] = path.Join(dir, name+".md")
+			assign(path.Join(dir, name), id)
+		}
+	}
+	assign("", "")
+	return paths
+}
+
+// slugify turns a title into a lowercase file name made of letters, digits
+// and dashes.
+func slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+			if b.Len() >= 60 {
+				break
+			}
+			continue
+		}
+		dash = true
+	}
+	if b.Len() == 0 {
+		return "untitled"
+	}
+	return b.String()
+}
+
+func pageFile(rec *notionsync.Record, paths map[string]string) string {
+	var b strings.Builder
+	if rec.Page != nil {
+		b.WriteString(notion.FrontMatter(rec.Page))
+	}
+	if md := rewriteLinks(rec.Markdown, paths[notion.NormalizeID(rec.ID)], paths); md != "" {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(md + "\n")
+	}
+	return b.String()
+}
+
+// databaseFile renders the index page of a database: its front matter and a
+// list of links to its rows.
+func databaseFile(rec *notionsync.Record, byID map[string]*notionsync.Record, paths map[string]string) string {
+	var b strings.Builder
+	if rec.Database != nil {
+		b.WriteString(notion.DatabaseFrontMatter(rec.Database))
+	}
+	from := paths[notion.NormalizeID(rec.ID)]
+	var items []string
+	for _, id := range rec.Children {
+		row := byID[notion.NormalizeID(id)]
+		if row == nil {
+			continue
+		}
+		title := row.Title
+		if title == "" {
+			title = "Untitled"
+		}
+		items = append(items, "- ["+notion.EscapeText(title)+"]("+relPath(from, paths[notion.NormalizeID(id)])+")")
+	}
+	if len(items) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(strings.Join(items, "\n") + "\n")
+	}
+	return b.String()
+}
+
+// notionLink matches links to Notion pages, both the canonical form
+// https://www.notion.so/<id> and workspace URLs ending in "-<id>", with an
+// optional query and block anchor.
+var notionLink = regexp.MustCompile(`https://(?:www\.)?notion\.so/(?:[^\s()<>]*[/-])?([0-9a-fA-F]{32})(?:\?[^\s()<>#]*)?(#[^\s()<>]*)?`)
+
+// rewriteLinks replaces links to exported pages in md with paths relative to
+// the file at from, keeping any block anchor.
+func rewriteLinks(md, from string, paths map[string]string) string {
+	return notionLink.ReplaceAllStringFunc(md, func(link string) string {
+		m := notionLink.FindStringSubmatch(link)
+		to, ok := paths[strings.ToLower(m[1])]
+		if !ok {
+			return link
+		}
+		return relPath(from, to) + m[2]
+	})
+}
+
+// relPath returns the slash-separated path of to relative to the directory
+// of the file from.
+func relPath(from, to string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(from)), filepath.FromSlash(to))
+	if err != nil {
+		return to
+	}
+	return filepath.ToSlash(rel)
+}
+
+// writeIfChanged writes content to name unless the file already holds it,
+// and reports whether it wrote.
+func writeIfChanged(name, content string) (bool, error) {
+	if existing, err := os.ReadFile(name); err == nil && bytes.Equal(existing, []byte(content)) {
+		return false, nil
+	}
+	if err := atomicfile.WriteFile(name, []byte(content)); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// removeEmptyDirs removes dir and its parents while they are empty, stopping
+// at root.
+func removeEmptyDirs(root, dir string) {
+	for dir != root && strings.HasPrefix(dir, root) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// manifest lists the slash-separated paths of the files written by an
+// export.
+type manifest struct {
+	Files []string `json:"files"`
+}
+
+func readManifest(dir string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return files, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export manifest: %w", err)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode export manifest: %w", err)
+	}
+	for _, p := range m.Files {
+		files[p] = true
+	}
+	return files, nil
+}
+
+// writeManifest saves the union of the given sets of paths.
+func writeManifest(dir string, sets ...map[string]bool) error {
+	m := manifest{Files: []string{}}
+	seen := make(map[string]bool)
+	for _, set := range sets {
+		for p := range set {
+			if !seen[p] {
+				seen[p] = true
+				m.Files = append(m.Files, p)
+			}
+		}
+	}
+	sort.Strings(m.Files)
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.WriteFile(filepath.Join(dir, manifestName), data); err != nil {
+		return fmt.Errorf("failed to write export manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package notionexport
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/internal/notiontest"
+)
+
+const (
+	rootID  = "11111111-1111-1111-1111-111111111111"
+	guideID = "22222222222222222222222222222222"
+	dbID    = "33333333333333333333333333333333"
+	rowID   = "44444444444444444444444444444444"
+)
+
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		if info.IsDir() && rel == storeDir {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && rel != manifestName {
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestExportWorkspace(t *testing.T) {
+	srv := notiontest.New(t)
+	srv.AddPage(notiontest.Page(rootID, "Team Home"))
+	srv.AddPage(notiontest.Page(guideID, "On-call Guide"))
+	srv.SetChildren(rootID,
+		notiontest.TextBlock("p1", "paragraph", "Start here."),
+		notiontest.Block(guideID, "child_page", map[string]any{"title": "On-call Guide"}),
+		notiontest.Block(dbID, "child_database", map[string]any{"title": "Incidents"}),
+	)
+	srv.SetChildren(guideID,
+		notiontest.Block("p2", "paragraph", map[string]any{"rich_text": []any{
+			notiontest.TextItem("Back to "),
+			map[string]any{
+				"type":        "mention",
+				"mention":     map[string]any{"type": "page", "page": map[string]any{"id": rootID}},
+				"plain_text":  "Team Home",
+				"annotations": map[string]any{},
+			},
+		}}),
+	)
+	srv.AddDatabase(notiontest.Database(dbID, "Incidents", map[string]any{
+		"Name": map[string]any{"type": "title", "title": map[string]any{}},
+	}), notiontest.Row(rowID, map[string]any{"Name": notiontest.TitleProperty("DB [outage]")}))
+	srv.SetChildren(rowID, notiontest.TextBlock("p3", "paragraph", "See the [guide](https://www.notion.so/acme/On-call-Guide-"+guideID+"?pvs=4)."))
+
+	ctx := context.Background()
+	client := notion.NewClient("secret", "", notion.WithHTTPClient(srv.HTTPClient()))
+	dir := t.TempDir()
+	res, err := ExportWorkspace(ctx, client, dir, []string{"https://www.notion.so/acme/Team-Home-11111111111111111111111111111111?pvs=4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Written != 4 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	want := []string{
+		"team-home.md",
+		"team-home/incidents.md",
+		"team-home/incidents/db-outage.md",
+		"team-home/on-call-guide.md",
+	}
+	if got := listFiles(t, dir); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("unexpected files: %v", got)
+	}
+
+	home := readFile(t, filepath.Join(dir, "team-home.md"))
+	if !strings.HasPrefix(home, "---\nid: "+rootID+"\ntitle: Team Home\n") ||
+		!strings.HasSuffix(home, "---\n\nStart here.\n\n[On-call Guide](team-home/on-call-guide.md) (page ID: "+guideID+")\n\n## Incidents\n") {
+		t.Fatalf("unexpected home page:\n%s", home)
+	}
+	if guide := readFile(t, filepath.Join(dir, "team-home/on-call-guide.md")); !strings.HasSuffix(guide, "Back to [Team Home](../team-home.md)\n") {
+		t.Fatalf("unexpected guide page:\n%s", guide)
+	}
+	index := readFile(t, filepath.Join(dir, "team-home/incidents.md"))
+	if !strings.Contains(index, "properties:\n  - Name\n") || !strings.HasSuffix(index, "---\n\n- [DB \\[outage\\]](incidents/db-outage.md)\n") {
+		t.Fatalf("unexpected database index:\n%s", index)
+	}
+	if row := readFile(t, filepath.Join(dir, "team-home/incidents/db-outage.md")); !strings.Contains(row, "(../on-call-guide.md)") {
+		t.Fatalf("expected the workspace link to be rewritten:\n%s", row)
+	}
+
+	// A second run rewrites nothing.
+	if res, err = ExportWorkspace(ctx, client, dir, []string{rootID}); err != nil {
+		t.Fatal(err)
+	}
+	if res.Written != 0 || res.Unchanged != 4 {
+		t.Fatalf("expected an idempotent export, got %+v", res)
+	}
+
+	// Renaming a page moves its file.
+	renamed := notiontest.Page(guideID, "Runbook")
+	renamed["last_edited_time"] = "2024-02-01T00:00:00.000Z"
+	srv.AddPage(renamed)
+	if res, err = ExportWorkspace(ctx, client, dir, []string{rootID}); err != nil {
+		t.Fatal(err)
+	}
+	if res.Removed != 1 {
+		t.Fatalf("expected the old file to be removed, got %+v", res)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "team-home/runbook.md")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRewriteLinks(t *testing.T) {
+	paths := map[string]string{guideID: "team-home/on-call-guide.md"}
+	md := "[Paging](https://www.notion.so/acme/On-call-Guide-" + guideID + "?pvs=4#" + rowID + ") and [elsewhere](https://www.notion.so/" + dbID + ")"
+	want := "[Paging](on-call-guide.md#" + rowID + ") and [elsewhere](https://www.notion.so/" + dbID + ")"
+	if got := rewriteLinks(md, "team-home/runbook.md", paths); got != want {
+		t.Fatalf("unexpected links:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"On-call Guide":   "on-call-guide",
+		"  Q3 / Planning": "q3-planning",
+		"Café Menü":       "café-menü",
+		"!!!":             "untitled",
+	}
+	for title, want := range tests {
+		if got := slugify(title); got != want {
+			t.Errorf("slugify(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
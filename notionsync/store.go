@@ -0,0 +1,249 @@
+package notionsync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/internal/atomicfile"
+)
+
+// Kinds of synced objects.
+const (
+	KindPage     = "page"
+	KindDatabase = "database"
+)
+
+// ErrNotFound is returned by a Store when no record has the requested ID.
+var ErrNotFound = errors.New("record not found")
+
+// Record is the local copy of a synced page or database.
+type Record struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	// ParentID is the ID of the synced page or database that contains this
+	// one, or "" for a root.
+	ParentID       string `json:"parent_id,omitempty"`
+	Title          string `json:"title"`
+	URL            string `json:"url"`
+	LastEditedTime string `json:"last_edited_time"`
+	// Children lists the IDs of the child pages and databases of a page, or
+	// the rows of a database, in order.
+	Children []string `json:"children,omitempty"`
+	// Markdown is the rendered body of a page. It is empty for databases.
+	Markdown string                 `json:"markdown,omitempty"`
+	Page     *notion.NotionPage     `json:"page,omitempty"`
+	Database *notion.NotionDatabase `json:"database,omitempty"`
+}
+
+// State is the progress of the last completed sync.
+type State struct {
+	// Roots are the IDs the store was synced from.
+	Roots []string `json:"roots"`
+	// Cursor is the latest last_edited_time seen; the next incremental sync
+	// looks at objects edited at or after it.
+	Cursor string `json:"cursor"`
+}
+
+// Store persists synced records and the sync state. Implementations must be
+// safe for concurrent use.
+type Store interface {
+	// Get returns the record with the given ID, or ErrNotFound.
+	Get(id string) (*Record, error)
+	// Put stores rec, replacing any record with the same ID.
+	Put(rec *Record) error
+	// Delete removes the record with the given ID if it exists.
+	Delete(id string) error
+	// List returns all records ordered by ID.
+	List() ([]*Record, error)
+	// LoadState returns the saved state, or nil if nothing was synced yet.
+	LoadState() (*State, error)
+	// SaveState saves the state of a completed sync.
+	SaveState(st *State) error
+}
+
+// MemoryStore is a Store that keeps records in memory.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+	state   *State
+}
+
+// NewMemoryStore returns an empty in-memory Store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]*Record)}
+}
+
+// Get returns a copy of the record with the given ID.
+func (m *MemoryStore) Get(id string) (*Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rec, ok := m.records[notion.NormalizeID(id)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *rec
+	return &cp, nil
+}
+
+// Put stores a copy of rec.
+func (m *MemoryStore) Put(rec *Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cp := *rec
+	m.records[notion.NormalizeID(rec.ID)] = &cp
+	return nil
+}
+
+// Delete removes the record with the given ID.
+func (m *MemoryStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.records, notion.NormalizeID(id))
+	return nil
+}
+
+// List returns copies of all records ordered by ID.
+func (m *MemoryStore) List() ([]*Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]*Record, 0, len(m.records))
+	for _, rec := range m.records {
+		cp := *rec
+		out = append(out, &cp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// LoadState returns the saved state.
+func (m *MemoryStore) LoadState() (*State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.state == nil {
+		return nil, nil
+	}
+	cp := *m.state
+	return &cp, nil
+}
+
+// SaveState saves the state.
+func (m *MemoryStore) SaveState(st *State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cp := *st
+	m.state = &cp
+	return nil
+}
+
+// DirStore is a Store that writes each record as a JSON file below a local
+// directory, next to a state.json file holding the sync state.
+type DirStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewDirStore returns a Store that keeps its files below dir, creating the
+// directory when the first file is written.
+func NewDirStore(dir string) *DirStore {
+	return &DirStore{dir: dir}
+}
+
+func (d *DirStore) recordPath(id string) string {
+	return filepath.Join(d.dir, "records", notion.NormalizeID(id)+".json")
+}
+
+// Get reads the record with the given ID.
+func (d *DirStore) Get(id string) (*Record, error) {
+	var rec Record
+	if err := readJSON(d.recordPath(id), &rec); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read record %s: %w", id, err)
+	}
+	return &rec, nil
+}
+
+// Put writes rec atomically.
+func (d *DirStore) Put(rec *Record) error {
+	if err := writeJSON(d.recordPath(rec.ID), rec); err != nil {
+		return fmt.Errorf("failed to write record %s: %w", rec.ID, err)
+	}
+	return nil
+}
+
+// Delete removes the record file with the given ID.
+func (d *DirStore) Delete(id string) error {
+	if err := os.Remove(d.recordPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete record %s: %w", id, err)
+	}
+	return nil
+}
+
+// List reads all records ordered by ID.
+func (d *DirStore) List() ([]*Record, error) {
+	paths, err := filepath.Glob(filepath.Join(d.dir, "records", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Record, 0, len(paths))
+	for _, p := range paths {
+		var rec Record
+		if err := readJSON(p, &rec); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read record: %w", err)
+		}
+		out = append(out, &rec)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// LoadState reads state.json.
+func (d *DirStore) LoadState() (*State, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var st State
+	if err := readJSON(filepath.Join(d.dir, "state.json"), &st); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read sync state: %w", err)
+	}
+	return &st, nil
+}
+
+// SaveState writes state.json atomically.
+func (d *DirStore) SaveState(st *State) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := writeJSON(filepath.Join(d.dir, "state.json"), st); err != nil {
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+	return nil
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeJSON writes v as indented JSON to path atomically.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, data)
+}
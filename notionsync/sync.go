@@ -0,0 +1,667 @@
+// Package notionsync keeps a local copy of a tree of Notion pages and
+// databases up to date.
+//
+// A Syncer starts from a set of root pages and databases, discovers every
+// page reachable through child pages, child databases and database rows,
+// and stores each one as a Record with its rendered Markdown. Later syncs
+// use the search API, sorted by last_edited_time, to refetch only what
+// changed since the previous run, and report each added, updated and
+// removed record to subscribers so indexes can be updated in place.
+package notionsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	notion "github.com/openai/notion-go-agents"
+)
+
+// EventType is the kind of change reported by an Event.
+type EventType string
+
+const (
+	EventAdded   EventType = "added"
+	EventUpdated EventType = "updated"
+	EventRemoved EventType = "removed"
+)
+
+// Event reports a change to a stored record. For EventRemoved, Record is the
+// record as it was last stored.
+type Event struct {
+	Type   EventType
+	Record *Record
+}
+
+// Result summarizes the changes made by a sync.
+type Result struct {
+	Added   int
+	Updated int
+	Removed int
+}
+
+// Option configures a Syncer.
+type Option func(*Syncer)
+
+// WithConverterOptions sets the options used to render page Markdown and to
+// fetch block trees, for example to include child database rows.
+func WithConverterOptions(opts ...notion.ConverterOption) Option {
+	return func(s *Syncer) {
+		s.converterOptions = opts
+	}
+}
+
+// Syncer mirrors the pages and databases below a set of roots into a Store.
+// Sync and FullSync must not run concurrently for the same store.
+type Syncer struct {
+	client           *notion.Client
+	store            Store
+	roots            []string
+	converterOptions []notion.ConverterOption
+
+	mu          sync.Mutex
+	subscribers []func(Event)
+}
+
+// New returns a Syncer that mirrors the pages and databases with the given
+// root IDs or URLs, and everything below them, into store. Roots that hold
+// no Notion ID are used as given.
+func New(client *notion.Client, store Store, roots []string, opts ...Option) *Syncer {
+	resolved := make([]string, len(roots))
+	for i, root := range roots {
+		resolved[i] = root
+		if id, err := notion.ParseID(root); err == nil {
+			resolved[i] = id
+		}
+	}
+	s := &Syncer{client: client, store: store, roots: resolved}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WorkspaceRoots returns the IDs of the top-level pages and databases shared
+// with the integration, for syncing the whole workspace.
+func WorkspaceRoots(ctx context.Context, client *notion.Client) ([]string, error) {
+	var roots []string
+	req := notion.NotionSearchRequest{PageSize: 100}
+	for {
+		resp, err := client.Search(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search workspace: %w", err)
+		}
+		for _, raw := range resp.Results {
+			var obj struct {
+				ID     string         `json:"id"`
+				Parent map[string]any `json:"parent"`
+			}
+			if err := json.Unmarshal(raw, &obj); err != nil {
+				continue
+			}
+			if obj.Parent["type"] == "workspace" {
+				roots = append(roots, obj.ID)
+			}
+		}
+		if !resp.HasMore || resp.NextCursor == "" {
+			return roots, nil
+		}
+		req.StartCursor = resp.NextCursor
+	}
+}
+
+// Subscribe registers fn to be called for every change, in order, once the
+// change has been stored. Calls happen on the goroutine running the sync.
+func (s *Syncer) Subscribe(fn func(Event)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, fn)
+}
+
+func (s *Syncer) emit(ev Event) {
+	s.mu.Lock()
+	subs := append([]func(Event){}, s.subscribers...)
+	s.mu.Unlock()
+	for _, fn := range subs {
+		fn(ev)
+	}
+}
+
+// Sync brings the store up to date. The first sync, or a sync after the roots
+// changed, walks everything below the roots like FullSync. Later syncs only
+// refetch pages and databases whose last_edited_time advanced since the last
+// run, discover pages added below them or to synced databases, and remove
+// pages that are no longer children of an edited parent, for example because
+// they were deleted, archived or moved elsewhere.
+//
+// Removing a row does not edit its database, so Sync also queries the rows of
+// every stored database it did not otherwise sync and removes the rows that
+// are gone. This fetches row metadata only, not page content.
+func (s *Syncer) Sync(ctx context.Context) (*Result, error) {
+	st, err := s.store.LoadState()
+	if err != nil {
+		return nil, err
+	}
+	if st == nil || !sameIDs(st.Roots, s.roots) {
+		return s.FullSync(ctx)
+	}
+	r := s.newRun(false, st.Cursor)
+	if err := r.searchChanges(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.refreshDatabases(ctx); err != nil {
+		return nil, err
+	}
+	return r.finish()
+}
+
+// FullSync walks every page and database below the roots, refetching the
+// content of those whose last_edited_time changed, and removes stored
+// records that are no longer reachable.
+func (s *Syncer) FullSync(ctx context.Context) (*Result, error) {
+	r := s.newRun(true, "")
+	for _, id := range s.roots {
+		if err := r.visit(ctx, id, "", ""); err != nil {
+			return nil, err
+		}
+	}
+	return r.finish()
+}
+
+// run holds the progress of a single sync.
+type run struct {
+	s    *Syncer
+	conv *notion.NotionMarkdownConverter
+	// full is set for FullSync, which revisits unchanged records to find
+	// everything still reachable.
+	full bool
+	// seen holds the normalized IDs of the records synced in this run.
+	seen map[string]bool
+	// claimed holds the normalized IDs of the roots and of the children
+	// listed by the parents synced in this run.
+	claimed map[string]bool
+	// orphans are the IDs of records that were dropped by their parent and
+	// are removed at the end of the run unless another parent claims them.
+	orphans []string
+	cursor  string
+	result  Result
+}
+
+func (s *Syncer) newRun(full bool, cursor string) *run {
+	r := &run{
+		s:       s,
+		conv:    notion.NewNotionMarkdownConverter(s.client, s.converterOptions...),
+		full:    full,
+		seen:    make(map[string]bool),
+		claimed: make(map[string]bool),
+		cursor:  cursor,
+	}
+	for _, id := range s.roots {
+		r.claimed[notion.NormalizeID(id)] = true
+	}
+	return r
+}
+
+// searchChanges applies the pages and databases edited since the cursor,
+// newest first.
+func (r *run) searchChanges(ctx context.Context) error {
+	since := r.cursor
+	req := notion.NotionSearchRequest{
+		Sort:     &notion.NotionSort{Direction: "descending", Timestamp: "last_edited_time"},
+		PageSize: 100,
+	}
+	for {
+		resp, err := r.s.client.Search(ctx, req)
+		if err != nil {
+			return fmt.Errorf("failed to search for changes: %w", err)
+		}
+		for _, raw := range resp.Results {
+			var obj struct {
+				Object         string `json:"object"`
+				LastEditedTime string `json:"last_edited_time"`
+			}
+			if err := json.Unmarshal(raw, &obj); err != nil {
+				continue
+			}
+			// Timestamps are ISO 8601 in UTC, so they sort as strings.
+			if obj.LastEditedTime < since {
+				return nil
+			}
+			if err := r.applyChange(ctx, obj.Object, raw); err != nil {
+				return err
+			}
+		}
+		if !resp.HasMore || resp.NextCursor == "" {
+			return nil
+		}
+		req.StartCursor = resp.NextCursor
+	}
+}
+
+// applyChange syncs a search result if it is a stored record or a new row
+// of a stored database. New pages elsewhere are found through their parent,
+// which is edited when a child page is added.
+func (r *run) applyChange(ctx context.Context, object string, raw json.RawMessage) error {
+	switch object {
+	case "page":
+		var pg notion.NotionPage
+		if err := json.Unmarshal(raw, &pg); err != nil || r.seen[notion.NormalizeID(pg.ID)] {
+			return nil
+		}
+		prev, err := r.get(pg.ID)
+		if err != nil {
+			return err
+		}
+		if prev != nil {
+			return r.syncPage(ctx, &pg, prev.ParentID)
+		}
+		dbID, _ := pg.Parent["database_id"].(string)
+		if dbID == "" {
+			return nil
+		}
+		db, err := r.get(dbID)
+		if err != nil || db == nil || db.Kind != KindDatabase {
+			return err
+		}
+		r.claimed[notion.NormalizeID(pg.ID)] = true
+		if err := r.syncPage(ctx, &pg, db.ID); err != nil {
+			return err
+		}
+		db.Children = append(db.Children, pg.ID)
+		return r.put(db, db)
+	case "database":
+		var db notion.NotionDatabase
+		if err := json.Unmarshal(raw, &db); err != nil {
+			return nil
+		}
+		prev, err := r.get(db.ID)
+		if err != nil || prev == nil {
+			return err
+		}
+		return r.syncDatabase(ctx, &db, prev.ParentID)
+	}
+	return nil
+}
+
+// visit syncs the page or database with the given ID. An empty kind means
+// the ID may be either.
+func (r *run) visit(ctx context.Context, id, kind, parentID string) error {
+	if r.seen[notion.NormalizeID(id)] {
+		return nil
+	}
+	if kind != KindDatabase {
+		pg, err := r.s.client.GetPage(ctx, id)
+		if err == nil {
+			return r.syncPage(ctx, pg, parentID)
+		}
+		if kind == KindPage || !notion.IsNotFound(err) && !isValidationError(err) {
+			return fmt.Errorf("failed to get page %s: %w", id, err)
+		}
+	}
+	db, err := r.s.client.GetDatabase(ctx, id)
+	if err != nil {
+		if parentID != "" && (notion.IsNotFound(err) || notion.IsLinkedDatabaseError(err)) {
+			// Linked database views appear as child databases but cannot be
+			// retrieved; skip them like databases that are not shared. Other
+			// errors may pass, so the run stops before dropping anything.
+			return nil
+		}
+		return fmt.Errorf("failed to get %s: %w", id, err)
+	}
+	return r.syncDatabase(ctx, db, parentID)
+}
+
+// syncPage stores a page, refetching its content if it changed, and visits
+// its child pages and databases.
+func (r *run) syncPage(ctx context.Context, pg *notion.NotionPage, parentID string) error {
+	key := notion.NormalizeID(pg.ID)
+	if r.seen[key] {
+		return nil
+	}
+	r.seen[key] = true
+	r.advance(pg.LastEditedTime)
+	if pg.Archived {
+		r.orphans = append(r.orphans, pg.ID)
+		delete(r.seen, key)
+		return nil
+	}
+	prev, err := r.get(pg.ID)
+	if err != nil {
+		return err
+	}
+	if prev != nil && prev.LastEditedTime == pg.LastEditedTime {
+		if err := r.reparent(prev, parentID); err != nil {
+			return err
+		}
+		if !r.full {
+			return nil
+		}
+		for _, id := range prev.Children {
+			kind := ""
+			if child, err := r.get(id); err == nil && child != nil {
+				kind = child.Kind
+			}
+			r.claimed[notion.NormalizeID(id)] = true
+			if err := r.visit(ctx, id, kind, pg.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	nodes, err := r.conv.GetBlockTree(ctx, pg.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get blocks of page %s: %w", pg.ID, err)
+	}
+	children := childObjects(nodes, nil)
+	rec := &Record{
+		ID:             pg.ID,
+		Kind:           KindPage,
+		ParentID:       parentID,
+		Title:          notion.ExtractNotionTitle(pg.Properties),
+		URL:            pg.URL,
+		LastEditedTime: pg.LastEditedTime,
+		Markdown:       r.conv.RenderMarkdown(nodes),
+		Page:           pg,
+	}
+	for _, child := range children {
+		rec.Children = append(rec.Children, child.id)
+	}
+	if err := r.put(rec, prev); err != nil {
+		return err
+	}
+	for _, child := range children {
+		r.claimed[notion.NormalizeID(child.id)] = true
+		if !r.full {
+			// Known children are refetched when their own edits show up in
+			// search; only a move needs recording here.
+			known, err := r.get(child.id)
+			if err != nil {
+				return err
+			}
+			if known != nil {
+				if err := r.reparent(known, pg.ID); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err := r.visit(ctx, child.id, child.kind, pg.ID); err != nil {
+			return err
+		}
+	}
+	if prev != nil {
+		r.dropMissing(prev.Children, rec.Children)
+	}
+	return nil
+}
+
+// syncDatabase stores a database with its current rows and syncs each row.
+func (r *run) syncDatabase(ctx context.Context, db *notion.NotionDatabase, parentID string) error {
+	key := notion.NormalizeID(db.ID)
+	if r.seen[key] {
+		return nil
+	}
+	r.seen[key] = true
+	r.advance(db.LastEditedTime)
+	rows, err := r.queryRows(ctx, db.ID)
+	if err != nil {
+		return err
+	}
+	prev, err := r.get(db.ID)
+	if err != nil {
+		return err
+	}
+	rec := &Record{
+		ID:             db.ID,
+		Kind:           KindDatabase,
+		ParentID:       parentID,
+		Title:          notion.PlainText(db.Title),
+		URL:            db.URL,
+		LastEditedTime: db.LastEditedTime,
+		Database:       db,
+	}
+	for _, row := range rows {
+		rec.Children = append(rec.Children, row.ID)
+		r.claimed[notion.NormalizeID(row.ID)] = true
+	}
+	if prev == nil || prev.LastEditedTime != rec.LastEditedTime || prev.ParentID != parentID || !sameIDs(prev.Children, rec.Children) {
+		if err := r.put(rec, prev); err != nil {
+			return err
+		}
+	}
+	for i := range rows {
+		if err := r.syncPage(ctx, &rows[i], db.ID); err != nil {
+			return err
+		}
+	}
+	if prev != nil {
+		r.dropMissing(prev.Children, rec.Children)
+	}
+	return nil
+}
+
+// refreshDatabases compares the rows of the stored databases not synced in
+// this run with their stored children, syncing new rows and dropping the rows
+// that were deleted or archived. A database that is no longer found is
+// dropped itself; other errors stop the run.
+func (r *run) refreshDatabases(ctx context.Context) error {
+	recs, err := r.s.store.List()
+	if err != nil {
+		return err
+	}
+	for _, rec := range recs {
+		if rec.Kind != KindDatabase || r.seen[notion.NormalizeID(rec.ID)] {
+			continue
+		}
+		rows, err := r.queryRows(ctx, rec.ID)
+		if notion.IsNotFound(err) {
+			r.orphans = append(r.orphans, rec.ID)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		var ids []string
+		for i := range rows {
+			ids = append(ids, rows[i].ID)
+			r.claimed[notion.NormalizeID(rows[i].ID)] = true
+			known, err := r.get(rows[i].ID)
+			if err != nil {
+				return err
+			}
+			if known == nil {
+				if err := r.syncPage(ctx, &rows[i], rec.ID); err != nil {
+					return err
+				}
+			}
+		}
+		if sameIDs(rec.Children, ids) {
+			continue
+		}
+		updated := *rec
+		updated.Children = ids
+		if err := r.put(&updated, rec); err != nil {
+			return err
+		}
+		r.dropMissing(rec.Children, ids)
+	}
+	return nil
+}
+
+// isValidationError reports whether err is the API rejecting a request, as it
+// does when asked for a page by a database's ID.
+func isValidationError(err error) bool {
+	var apiErr *notion.APIError
+	return errors.As(err, &apiErr) && apiErr.Code == "validation_error"
+}
+
+func (r *run) queryRows(ctx context.Context, databaseID string) ([]notion.NotionPage, error) {
+	var rows []notion.NotionPage
+	req := notion.NotionDatabaseQueryRequest{PageSize: 100}
+	for {
+		resp, err := r.s.client.QueryDatabase(ctx, databaseID, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query database %s: %w", databaseID, err)
+		}
+		for _, raw := range resp.Results {
+			var pg notion.NotionPage
+			if err := json.Unmarshal(raw, &pg); err != nil {
+				continue
+			}
+			rows = append(rows, pg)
+		}
+		if !resp.HasMore || resp.NextCursor == "" {
+			return rows, nil
+		}
+		req.StartCursor = resp.NextCursor
+	}
+}
+
+// finish removes records that are no longer reachable and saves the state.
+func (r *run) finish() (*Result, error) {
+	for _, id := range r.orphans {
+		if r.claimed[notion.NormalizeID(id)] {
+			continue
+		}
+		if err := r.remove(id); err != nil {
+			return nil, err
+		}
+	}
+	if r.full {
+		recs, err := r.s.store.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range recs {
+			if !r.seen[notion.NormalizeID(rec.ID)] {
+				if err := r.remove(rec.ID); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if err := r.s.store.SaveState(&State{Roots: r.s.roots, Cursor: r.cursor}); err != nil {
+		return nil, err
+	}
+	return &r.result, nil
+}
+
+// remove deletes a record and the records below it that no parent synced in
+// this run claimed.
+func (r *run) remove(id string) error {
+	rec, err := r.get(id)
+	if err != nil || rec == nil {
+		return err
+	}
+	if err := r.s.store.Delete(rec.ID); err != nil {
+		return err
+	}
+	r.result.Removed++
+	r.s.emit(Event{Type: EventRemoved, Record: rec})
+	for _, childID := range rec.Children {
+		if r.claimed[notion.NormalizeID(childID)] {
+			continue
+		}
+		child, err := r.get(childID)
+		if err != nil {
+			return err
+		}
+		if child != nil && notion.NormalizeID(child.ParentID) == notion.NormalizeID(rec.ID) {
+			if err := r.remove(childID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reparent records that a stored record now belongs to parentID.
+func (r *run) reparent(rec *Record, parentID string) error {
+	if rec.ParentID == parentID {
+		return nil
+	}
+	moved := *rec
+	moved.ParentID = parentID
+	return r.put(&moved, rec)
+}
+
+// get returns the stored record with the given ID, or nil if there is none.
+func (r *run) get(id string) (*Record, error) {
+	rec, err := r.s.store.Get(id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return rec, err
+}
+
+// put stores rec and reports it as added, or as updated when prev is set.
+func (r *run) put(rec, prev *Record) error {
+	if err := r.s.store.Put(rec); err != nil {
+		return err
+	}
+	ev := Event{Type: EventUpdated, Record: rec}
+	if prev == nil {
+		ev.Type = EventAdded
+		r.result.Added++
+	} else {
+		r.result.Updated++
+	}
+	r.s.emit(ev)
+	return nil
+}
+
+func (r *run) advance(lastEdited string) {
+	if lastEdited > r.cursor {
+		r.cursor = lastEdited
+	}
+}
+
+// dropMissing marks the children in before that are not in after as orphans.
+func (r *run) dropMissing(before, after []string) {
+	keep := make(map[string]bool, len(after))
+	for _, id := range after {
+		keep[notion.NormalizeID(id)] = true
+	}
+	for _, id := range before {
+		if !keep[notion.NormalizeID(id)] {
+			r.orphans = append(r.orphans, id)
+		}
+	}
+}
+
+type childObject struct {
+	id, kind string
+}
+
+// childObjects collects the child pages and databases found in a block tree,
+// without looking inside them.
+func childObjects(nodes []notion.BlockNode, out []childObject) []childObject {
+	for _, node := range nodes {
+		blockType, _ := node.Block["type"].(string)
+		id, _ := node.Block["id"].(string)
+		switch blockType {
+		case "child_page":
+			out = append(out, childObject{id: id, kind: KindPage})
+		case "child_database":
+			out = append(out, childObject{id: id, kind: KindDatabase})
+		default:
+			out = childObjects(node.Children, out)
+		}
+	}
+	return out
+}
+
+func sameIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if notion.NormalizeID(a[i]) != notion.NormalizeID(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,173 @@
+package notionsync
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/internal/notiontest"
+)
+
+const edited = "2024-02-01T00:00:00.000Z"
+
+func newWorkspace(t *testing.T) *notiontest.Server {
+	srv := notiontest.New(t)
+	srv.AddPage(notiontest.Page("root", "Home"))
+	srv.AddPage(notiontest.Page("a", "Alpha"))
+	srv.AddPage(notiontest.Page("b", "Beta"))
+	srv.SetChildren("root",
+		notiontest.TextBlock("p1", "paragraph", "Welcome"),
+		notiontest.Block("a", "child_page", map[string]any{"title": "Alpha"}),
+		notiontest.Block("db1", "child_database", map[string]any{"title": "Tasks"}),
+	)
+	srv.SetChildren("a", notiontest.Block("b", "child_page", map[string]any{"title": "Beta"}))
+	srv.AddDatabase(notiontest.Database("db1", "Tasks", map[string]any{
+		"Name": map[string]any{"type": "title", "title": map[string]any{}},
+	}),
+		notiontest.Row("r1", map[string]any{"Name": notiontest.TitleProperty("One")}),
+		notiontest.Row("r2", map[string]any{"Name": notiontest.TitleProperty("Two")}),
+	)
+	return srv
+}
+
+// recorder collects events as "type:id" strings.
+type recorder []string
+
+func (r *recorder) record(ev Event) {
+	*r = append(*r, string(ev.Type)+":"+ev.Record.ID)
+}
+
+func (r *recorder) take() string {
+	events := append([]string{}, *r...)
+	sort.Strings(events)
+	*r = nil
+	return strings.Join(events, " ")
+}
+
+func TestSync(t *testing.T) {
+	srv := newWorkspace(t)
+	ctx := context.Background()
+	client := notion.NewClient("secret", "", notion.WithHTTPClient(srv.HTTPClient()))
+	dir := t.TempDir()
+
+	var events recorder
+	s := New(client, NewDirStore(dir), []string{"root"})
+	s.Subscribe(events.record)
+	res, err := s.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Added != 6 || events.take() != "added:a added:b added:db1 added:r1 added:r2 added:root" {
+		t.Fatalf("unexpected first sync: %+v", res)
+	}
+	root, err := NewDirStore(dir).Get("root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Title != "Home" || root.Markdown != "Welcome\n\n## Alpha\n\n## Tasks" || strings.Join(root.Children, ",") != "a,db1" {
+		t.Fatalf("unexpected root record: %+v", root)
+	}
+
+	// Nothing changed: the next sync fetches no content.
+	s = New(client, NewDirStore(dir), []string{"root"})
+	s.Subscribe(events.record)
+	fetches := srv.Requests("GET /v1/blocks/root/children")
+	if res, err = s.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if *res != (Result{}) || srv.Requests("GET /v1/blocks/root/children") != fetches {
+		t.Fatalf("expected no changes, got %+v", res)
+	}
+
+	// Beta moves out of Alpha and a row is added to the database.
+	alpha := notiontest.Page("a", "Alpha")
+	alpha["last_edited_time"] = edited
+	srv.AddPage(alpha)
+	srv.SetChildren("a", notiontest.TextBlock("p2", "paragraph", "Moved Beta away"))
+	r3 := notiontest.Row("r3", map[string]any{"Name": notiontest.TitleProperty("Three")})
+	r3["last_edited_time"] = edited
+	srv.AddDatabase(notiontest.Database("db1", "Tasks", nil),
+		notiontest.Row("r1", map[string]any{"Name": notiontest.TitleProperty("One")}),
+		notiontest.Row("r2", map[string]any{"Name": notiontest.TitleProperty("Two")}),
+		r3,
+	)
+	if _, err = s.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := events.take(); got != "added:r3 removed:b updated:a updated:db1" {
+		t.Fatalf("unexpected incremental events: %s", got)
+	}
+	st, _ := NewDirStore(dir).LoadState()
+	if st.Cursor != edited {
+		t.Fatalf("expected the cursor to advance, got %q", st.Cursor)
+	}
+
+	// A deleted row does not edit its database but is still removed.
+	srv.AddDatabase(notiontest.Database("db1", "Tasks", nil),
+		notiontest.Row("r1", map[string]any{"Name": notiontest.TitleProperty("One")}),
+		r3,
+	)
+	srv.RemovePage("r2")
+	if _, err = s.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := events.take(); got != "removed:r2 updated:db1" {
+		t.Fatalf("unexpected events for a deleted row: %s", got)
+	}
+	if _, err = s.FullSync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := events.take(); got != "" {
+		t.Fatalf("unexpected full sync events: %s", got)
+	}
+	recs, err := NewDirStore(dir).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, rec := range recs {
+		ids = append(ids, rec.ID)
+	}
+	if got := strings.Join(ids, ","); got != "a,db1,r1,r3,root" {
+		t.Fatalf("unexpected records: %s", got)
+	}
+}
+
+func TestNewResolvesRoots(t *testing.T) {
+	s := New(nil, nil, []string{"https://www.notion.so/acme/Home-1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6?pvs=4", "root"})
+	if got := strings.Join(s.roots, " "); got != "1a2b3c4d-5e6f-47a8-b9c0-d1e2f3a4b5c6 root" {
+		t.Fatalf("unexpected roots: %s", got)
+	}
+}
+
+func TestSyncKeepsStoreOnAPIErrors(t *testing.T) {
+	srv := newWorkspace(t)
+	ctx := context.Background()
+	client := notion.NewClient("secret", "", notion.WithHTTPClient(srv.HTTPClient()))
+	store := NewDirStore(t.TempDir())
+	if _, err := New(client, store, []string{"root"}).Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failing database must not look deleted to either kind of sync.
+	srv.SetFailure("GET /v1/databases/db1", notiontest.Failure{Status: 500, Code: "internal_server_error"})
+	srv.SetFailure("POST /v1/databases/db1/query", notiontest.Failure{Status: 500, Code: "internal_server_error"})
+	var events recorder
+	s := New(client, store, []string{"root"})
+	s.Subscribe(events.record)
+	if _, err := s.FullSync(ctx); err == nil {
+		t.Fatal("expected the full sync to fail")
+	}
+	if _, err := s.Sync(ctx); err == nil {
+		t.Fatal("expected the sync to fail")
+	}
+	recs, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 6 || len(events) != 0 {
+		t.Fatalf("expected the store to be left alone, got %d records and events %v", len(recs), events)
+	}
+}
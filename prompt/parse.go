@@ -0,0 +1,86 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openai/notion-go-agents/internal/mdsplit"
+)
+
+// section is a heading and the blocks under it, up to the next heading.
+type section struct {
+	// level is the heading's level, or 0 for the blocks before the first
+	// heading of a page.
+	level int
+	title string
+	// link points at the heading's block, or at the page.
+	link   string
+	blocks []string
+}
+
+// parseSections splits Markdown into sections at "#", "##" and "###" lines
+// outside code fences, and each section into blocks separated by blank
+// lines. link returns the link for the heading on a line, counted from 1.
+// Long tables and code blocks are trimmed as they are parsed.
+func (b *Builder) parseSections(md string, link func(line int) string) []section {
+	var out []section
+	cur := section{}
+	for _, part := range mdsplit.Split(md) {
+		if part.Level == 0 {
+			if text := part.Text(); text != "" {
+				cur.blocks = append(cur.blocks, b.trimBlock(text))
+			}
+			continue
+		}
+		if cur.level > 0 || len(cur.blocks) > 0 {
+			out = append(out, cur)
+		}
+		cur = section{level: part.Level, title: part.Title, link: link(part.Line)}
+	}
+	if cur.level > 0 || len(cur.blocks) > 0 {
+		out = append(out, cur)
+	}
+	return out
+}
+
+// trimBlock shortens code blocks and tables over the builder's limits,
+// noting how much was left out.
+func (b *Builder) trimBlock(text string) string {
+	lines := strings.Split(text, "\n")
+	switch {
+	case strings.HasPrefix(lines[0], "```") && len(lines) > 2:
+		body := lines[1 : len(lines)-1]
+		if strings.HasPrefix(lines[len(lines)-1], "```") && len(body) > b.maxCodeLines {
+			kept := append([]string{lines[0]}, body[:b.maxCodeLines]...)
+			kept = append(kept, "… ("+more(len(body)-b.maxCodeLines, "line")+")", lines[len(lines)-1])
+			return strings.Join(kept, "\n")
+		}
+	case isTable(lines):
+		// The header and its separator row come first.
+		if rows := len(lines) - 2; rows > b.maxTableRows {
+			kept := append(lines[:2+b.maxTableRows:2+b.maxTableRows], "… ("+more(rows-b.maxTableRows, "row")+")")
+			return strings.Join(kept, "\n")
+		}
+	}
+	return text
+}
+
+func isTable(lines []string) bool {
+	if len(lines) < 2 {
+		return false
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "|") {
+			return false
+		}
+	}
+	return true
+}
+
+// more describes n left out things, such as "3 more rows omitted".
+func more(n int, what string) string {
+	if n != 1 {
+		what += "s"
+	}
+	return fmt.Sprintf("%d more %s omitted", n, what)
+}
@@ -0,0 +1,303 @@
+// Package prompt assembles Notion pages into prompt-ready context for LLM
+// calls within a token budget.
+//
+// A Builder takes whole pages (notion.PageContent) or retrieved chunks
+// (chunk.Chunk) and lays them out as one Markdown document: a table of
+// contents linking to each heading's block, then every page under its title
+// and source URL. When the content does not fit the budget, headings are
+// kept first, so the outline of all pages survives, and blocks are then
+// added a round at a time, the next block of each section in turn, so that
+// sections keep their leading blocks. Long tables and code blocks are
+// trimmed, and everything left out is replaced by an "omitted" marker so the
+// model knows the context is partial.
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/chunk"
+)
+
+// Option configures a Builder.
+type Option func(*Builder)
+
+// WithTokenCounter sets the counter used to measure the budget. The default
+// is ApproxCounter.
+func WithTokenCounter(tc TokenCounter) Option {
+	return func(b *Builder) {
+		b.counter = tc
+	}
+}
+
+// WithMaxTableRows sets how many rows of a table are kept. The default is 20.
+func WithMaxTableRows(n int) Option {
+	return func(b *Builder) {
+		b.maxTableRows = n
+	}
+}
+
+// WithMaxCodeLines sets how many lines of a code block are kept. The default
+// is 40.
+func WithMaxCodeLines(n int) Option {
+	return func(b *Builder) {
+		b.maxCodeLines = n
+	}
+}
+
+// WithoutTableOfContents leaves out the table of contents.
+func WithoutTableOfContents() Option {
+	return func(b *Builder) {
+		b.toc = false
+	}
+}
+
+// Builder collects pages and chunks and renders them within a budget.
+type Builder struct {
+	counter      TokenCounter
+	maxTableRows int
+	maxCodeLines int
+	toc          bool
+
+	docs []*document
+}
+
+// document is a page or the chunks of one page.
+type document struct {
+	pageID   string
+	title    string
+	url      string
+	sections []section
+}
+
+// New returns an empty Builder configured by opts.
+func New(opts ...Option) *Builder {
+	b := &Builder{counter: ApproxCounter, maxTableRows: 20, maxCodeLines: 40, toc: true}
+	for _, opt := range opts {
+		opt(b)
+	}
+	b.maxTableRows = max(b.maxTableRows, 1)
+	b.maxCodeLines = max(b.maxCodeLines, 1)
+	return b
+}
+
+// AddPage adds a page. Pages converted with notion.WithSourceMap get table of
+// contents entries that link to their heading blocks; others link to the
+// page.
+func (b *Builder) AddPage(pc *notion.PageContent) {
+	d := b.document(pc.ID, pc.Title, pc.URL)
+	d.sections = append(d.sections, b.parseSections(pc.Markdown, func(line int) string {
+		if id := pc.SourceMap.BlockAtLine(line); id != "" {
+			return notion.BlockURL(pc.ID, id)
+		}
+		return d.url
+	})...)
+}
+
+// AddChunks adds chunks, such as retrieval results, grouped by page in the
+// order their pages first appear. A chunk's breadcrumb supplies its page's
+// title, and headings link to the chunk's first block.
+func (b *Builder) AddChunks(chunks []chunk.Chunk) {
+	for _, c := range chunks {
+		title := ""
+		if len(c.Breadcrumb) > 0 {
+			title = c.Breadcrumb[0]
+		}
+		d := b.document(c.PageID, title, notion.BlockURL(c.PageID, ""))
+		link := c.URL()
+		d.sections = append(d.sections, b.parseSections(c.Text, func(int) string { return link })...)
+	}
+}
+
+// document returns the document of a page, adding it when it is new.
+func (b *Builder) document(pageID, title, url string) *document {
+	for _, d := range b.docs {
+		if d.pageID == pageID {
+			return d
+		}
+	}
+	if title == "" {
+		title = "Untitled"
+	}
+	d := &document{pageID: pageID, title: title, url: url}
+	b.docs = append(b.docs, d)
+	return d
+}
+
+// layout records how much of each section is rendered.
+type layout struct {
+	toc bool
+	// blocks holds, per document and section, the number of leading blocks
+	// kept, or -1 when the section is left out.
+	blocks [][]int
+}
+
+// Build renders the collected content as Markdown within budget tokens. When
+// even the page titles and headings do not fit, trailing sections are left
+// out. A budget of 0 or less renders everything.
+func (b *Builder) Build(budget int) string {
+	l := layout{toc: b.toc, blocks: make([][]int, len(b.docs))}
+	for i, d := range b.docs {
+		l.blocks[i] = make([]int, len(d.sections))
+	}
+	if budget <= 0 {
+		for i, d := range b.docs {
+			for j, s := range d.sections {
+				l.blocks[i][j] = len(s.blocks)
+			}
+		}
+		return b.render(l)
+	}
+	fits := func() bool { return b.counter.CountTokens(b.render(l)) <= budget }
+
+	// Headings first: drop the table of contents, then trailing sections,
+	// until the outline fits.
+	if !fits() {
+		l.toc = false
+	}
+	for i := len(b.docs) - 1; i >= 0 && !fits(); i-- {
+		for j := len(b.docs[i].sections) - 1; j >= 0 && !fits(); j-- {
+			l.blocks[i][j] = -1
+		}
+	}
+
+	// Then blocks, one per section per round, estimating each block's cost
+	// and checking the estimate against the rendered document at the end. A
+	// section stops growing at the first block that does not fit.
+	used := b.counter.CountTokens(b.render(l))
+	sep := b.counter.CountTokens("\n\n")
+	type ref struct{ doc, sec int }
+	var added []ref
+	full := make(map[ref]bool)
+	for progress := true; progress; {
+		progress = false
+		for i, d := range b.docs {
+			for j, s := range d.sections {
+				r := ref{i, j}
+				n := l.blocks[i][j]
+				if n < 0 || n >= len(s.blocks) || full[r] {
+					continue
+				}
+				cost := b.counter.CountTokens(s.blocks[n]) + sep
+				if n == len(s.blocks)-1 {
+					// The last block replaces the section's omitted marker.
+					cost -= b.counter.CountTokens(omitted(1, "block")) + sep
+				}
+				if used+cost > budget {
+					full[r] = true
+					continue
+				}
+				used += cost
+				l.blocks[i][j]++
+				added = append(added, r)
+				progress = true
+			}
+		}
+	}
+	for len(added) > 0 && !fits() {
+		last := added[len(added)-1]
+		l.blocks[last.doc][last.sec]--
+		added = added[:len(added)-1]
+	}
+	return b.render(l)
+}
+
+// render lays out the documents as l describes.
+func (b *Builder) render(l layout) string {
+	var parts []string
+	if l.toc {
+		if toc := b.tableOfContents(l); toc != "" {
+			parts = append(parts, toc)
+		}
+	}
+	omittedDocs := 0
+	for i, d := range b.docs {
+		var doc []string
+		omittedSections := 0
+		for j, s := range d.sections {
+			n := l.blocks[i][j]
+			if n < 0 {
+				omittedSections++
+				continue
+			}
+			if s.level > 0 {
+				// Page titles are level 1, so headings move down a level.
+				doc = append(doc, strings.Repeat("#", min(s.level+1, 6))+" "+s.title)
+			}
+			doc = append(doc, s.blocks[:n]...)
+			if rest := len(s.blocks) - n; rest > 0 {
+				doc = append(doc, omitted(rest, "block"))
+			}
+		}
+		if len(doc) == 0 && omittedSections > 0 {
+			omittedDocs++
+			continue
+		}
+		if omittedSections > 0 {
+			doc = append(doc, omitted(omittedSections, "section"))
+		}
+		header := "# " + d.title
+		if d.url != "" {
+			header += "\nSource: " + d.url
+		}
+		parts = append(parts, header)
+		parts = append(parts, doc...)
+	}
+	if omittedDocs > 0 {
+		parts = append(parts, omitted(omittedDocs, "page"))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// tableOfContents lists the pages and the headings of their rendered
+// sections, linked to their blocks.
+func (b *Builder) tableOfContents(l layout) string {
+	var lines []string
+	headings := 0
+	for i, d := range b.docs {
+		// Headings are indented below the page by their depth under the
+		// page's shallowest heading, which need not be level 1.
+		top := 0
+		for j, s := range d.sections {
+			if l.blocks[i][j] >= 0 && s.level > 0 && (top == 0 || s.level < top) {
+				top = s.level
+			}
+		}
+		var entries []string
+		kept := false
+		for j, s := range d.sections {
+			if l.blocks[i][j] < 0 {
+				continue
+			}
+			kept = true
+			if s.level > 0 {
+				entries = append(entries, strings.Repeat("  ", s.level-top+1)+"- "+link(s.title, s.link))
+			}
+		}
+		if !kept {
+			continue
+		}
+		headings += len(entries)
+		lines = append(lines, "- "+link(d.title, d.url))
+		lines = append(lines, entries...)
+	}
+	if headings == 0 {
+		return ""
+	}
+	return "Contents:\n" + strings.Join(lines, "\n")
+}
+
+func link(text, url string) string {
+	if url == "" {
+		return text
+	}
+	return "[" + text + "](" + url + ")"
+}
+
+func omitted(n int, what string) string {
+	if n != 1 {
+		what += "s"
+	}
+	return fmt.Sprintf("[… %d %s omitted]", n, what)
+}
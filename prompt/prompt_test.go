@@ -0,0 +1,124 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/chunk"
+)
+
+func runbook() *notion.PageContent {
+	return &notion.PageContent{
+		ID:       "1a2b",
+		Title:    "Runbook",
+		URL:      "https://www.notion.so/1a2b",
+		Markdown: "Intro.\n\n## Setup\n\n```sh\na\nb\nc\n```\n\n| K | V |\n| --- | --- |\n| 1 | 1 |\n| 2 | 2 |\n| 3 | 3 |\n\n## Escalation\n\nPage the lead.\n\nThen the manager.",
+		SourceMap: notion.SourceMap{
+			{BlockID: "h-setup", StartLine: 3, EndLine: 3},
+			{BlockID: "h-esc", StartLine: 17, EndLine: 17},
+		},
+	}
+}
+
+func TestBuildAll(t *testing.T) {
+	b := New(WithMaxCodeLines(2), WithMaxTableRows(2))
+	b.AddPage(runbook())
+	want := `Contents:
+- [Runbook](https://www.notion.so/1a2b)
+  - [Setup](https://www.notion.so/1a2b#hsetup)
+  - [Escalation](https://www.notion.so/1a2b#hesc)
+
+# Runbook
+Source: https://www.notion.so/1a2b
+
+Intro.
+
+### Setup
+
+` + "```sh\na\nb\n… (1 more line omitted)\n```" + `
+
+| K | V |
+| --- | --- |
+| 1 | 1 |
+| 2 | 2 |
+… (1 more row omitted)
+
+### Escalation
+
+Page the lead.
+
+Then the manager.`
+	if got := b.Build(0); got != want {
+		t.Fatalf("unexpected context:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTableOfContentsLevels(t *testing.T) {
+	b := New()
+	b.AddPage(&notion.PageContent{ID: "c", Title: "Runbook", Markdown: "## Setup\n\nInstall it.\n\n### Linux\n\nUse apt.\n\n## Escalation\n\nPage the lead."})
+	want := "Contents:\n- Runbook\n  - Setup\n    - Linux\n  - Escalation\n\n"
+	if got := b.Build(0); !strings.HasPrefix(got, want) {
+		t.Fatalf("unexpected contents:\n%s", got)
+	}
+}
+
+func TestBuildBudget(t *testing.T) {
+	words := TokenCounterFunc(func(s string) int { return len(strings.Fields(s)) })
+	b := New(WithTokenCounter(words), WithoutTableOfContents())
+	b.AddPage(runbook())
+	b.AddPage(&notion.PageContent{ID: "ffff", Title: "Glossary", Markdown: "## Terms\n\nSLO means service level objective."})
+
+	got := b.Build(35)
+	if n := words.CountTokens(got); n > 35 {
+		t.Fatalf("context has %d tokens, over the budget:\n%s", n, got)
+	}
+	for _, want := range []string{"# Runbook", "### Setup", "```sh", "### Escalation", "Then the manager.", "# Glossary", "### Terms", "SLO means", "[… 1 block omitted]"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "| K | V |") {
+		t.Errorf("expected the table to be omitted:\n%s", got)
+	}
+
+	// Too small for every heading: trailing sections and pages go first.
+	got = b.Build(20)
+	if n := words.CountTokens(got); n > 20 || !strings.Contains(got, "Intro.") || !strings.Contains(got, "[… 2 sections omitted]") || !strings.Contains(got, "[… 1 page omitted]") {
+		t.Fatalf("unexpected context with %d tokens:\n%s", n, got)
+	}
+}
+
+func TestAddChunks(t *testing.T) {
+	b := New()
+	b.AddChunks([]chunk.Chunk{
+		{PageID: "aaaa", Breadcrumb: []string{"Runbook", "Setup"}, Text: "## Setup\n\nInstall it.", BlockIDs: []string{"h1", "p1"}},
+		{PageID: "bbbb", Breadcrumb: []string{"Glossary"}, Text: "SLO: objective."},
+		{PageID: "aaaa", Index: 2, Breadcrumb: []string{"Runbook", "Escalation"}, Text: "## Escalation\n\nPage the lead.", BlockIDs: []string{"h2"}},
+	})
+	got := b.Build(0)
+	want := `Contents:
+- [Runbook](https://www.notion.so/aaaa)
+  - [Setup](https://www.notion.so/aaaa#h1)
+  - [Escalation](https://www.notion.so/aaaa#h2)
+- [Glossary](https://www.notion.so/bbbb)
+
+# Runbook
+Source: https://www.notion.so/aaaa
+
+### Setup
+
+Install it.
+
+### Escalation
+
+Page the lead.
+
+# Glossary
+Source: https://www.notion.so/bbbb
+
+SLO: objective.`
+	if got != want {
+		t.Fatalf("unexpected context:\n%s\nwant:\n%s", got, want)
+	}
+}
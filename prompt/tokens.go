@@ -0,0 +1,32 @@
+package prompt
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// TokenCounter counts the tokens a model would see for a text.
+type TokenCounter interface {
+	CountTokens(text string) int
+}
+
+// TokenCounterFunc adapts a function to a TokenCounter, for example a
+// wrapper around a model's tokenizer.
+type TokenCounterFunc func(text string) int
+
+// CountTokens calls f.
+func (f TokenCounterFunc) CountTokens(text string) int {
+	return f(text)
+}
+
+// ApproxCounter estimates tokens without a tokenizer: about four characters
+// per token, but never fewer than the number of space-separated words.
+var ApproxCounter TokenCounter = TokenCounterFunc(approxTokens)
+
+func approxTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	byChars := (utf8.RuneCountInString(text) + 3) / 4
+	return max(byChars, len(strings.Fields(text)))
+}
@@ -0,0 +1,88 @@
+package retrieve
+
+import (
+	"context"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/fulltext"
+	"github.com/openai/notion-go-agents/vector"
+)
+
+// BackendFunc adapts a function to the Backend interface.
+type BackendFunc struct {
+	BackendName string
+	Fn          func(ctx context.Context, query string, limit int) ([]Candidate, error)
+}
+
+// Name implements Backend.
+func (f BackendFunc) Name() string { return f.BackendName }
+
+// Retrieve implements Backend.
+func (f BackendFunc) Retrieve(ctx context.Context, query string, limit int) ([]Candidate, error) {
+	return f.Fn(ctx, query, limit)
+}
+
+// NotionSearch returns a backend named "notion" that uses the Notion search
+// API and returns whole pages. Each result costs a conversion of the page,
+// fetching all of its blocks, so every query converts as many pages as the
+// retriever requests candidates (see WithCandidates), and up to a full page
+// of search results when that is 0. Notion matches mostly titles, so it
+// works best alongside local backends.
+func NotionSearch(client *notion.Client) Backend {
+	return BackendFunc{BackendName: "notion", Fn: func(ctx context.Context, query string, limit int) ([]Candidate, error) {
+		pages, err := notion.SearchWorkspace(ctx, client, notion.NotionSearchRequest{Query: query}, limit)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]Candidate, len(pages))
+		for i, pc := range pages {
+			out[i] = Candidate{PageID: pc.ID, Title: pc.Title, URL: pc.URL, Text: pc.Markdown}
+		}
+		return out, nil
+	}}
+}
+
+// Keyword returns a backend named "keyword" over a full-text searcher such as
+// a fulltext.Index. Candidates are whole pages whose text is the search
+// snippet, with the snippet's block as BlockIDs when the hit has one.
+func Keyword(s fulltext.Searcher) Backend {
+	return BackendFunc{BackendName: "keyword", Fn: func(ctx context.Context, query string, limit int) ([]Candidate, error) {
+		hits, err := s.Search(ctx, query, limit)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]Candidate, len(hits))
+		for i, h := range hits {
+			out[i] = Candidate{PageID: h.Page.ID, Title: h.Page.Title, URL: h.Page.URL, Text: h.Snippet, Score: h.Score}
+			if h.BlockID != "" {
+				out[i].BlockIDs = []string{h.BlockID}
+			}
+		}
+		return out, nil
+	}}
+}
+
+// Vector returns a backend named "vector" over a vector index. Candidates are
+// the index's chunks.
+func Vector(ix *vector.Index) Backend {
+	return BackendFunc{BackendName: "vector", Fn: func(ctx context.Context, query string, limit int) ([]Candidate, error) {
+		res, err := ix.Search(ctx, query, limit)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]Candidate, len(res))
+		for i, r := range res {
+			out[i] = Candidate{
+				PageID:     r.PageID,
+				ChunkID:    r.ID,
+				Title:      r.Title,
+				URL:        r.URL,
+				Breadcrumb: r.Breadcrumb,
+				Text:       r.Text,
+				BlockIDs:   r.BlockIDs,
+				Score:      r.Score,
+			}
+		}
+		return out, nil
+	}}
+}
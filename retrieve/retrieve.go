@@ -0,0 +1,238 @@
+// Package retrieve combines several search backends into one ranked list of
+// chunks for LLM agents.
+//
+// A Retriever sends a query to each configured Backend at once, such as
+// Notion's own search, a local fulltext.Index and a vector.Index, and merges
+// their rankings with reciprocal rank fusion. Results are deduplicated by
+// chunk, and whole-page results are folded into the best chunk of the same
+// page, so every result carries the text to show an agent along with the
+// backends that found it.
+package retrieve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	notion "github.com/openai/notion-go-agents"
+)
+
+// Candidate is a page or chunk returned by a Backend.
+type Candidate struct {
+	PageID string
+	// ChunkID identifies a chunk within the page, or is "" when the
+	// candidate is a whole page.
+	ChunkID    string
+	Title      string
+	URL        string
+	Breadcrumb []string
+	Text       string
+	BlockIDs   []string
+	// Score is the backend's own score, kept for provenance only; ranking
+	// uses the candidate's position.
+	Score float64
+}
+
+// CitationURL returns a link to the candidate's first block within its page,
+// or the page's URL when the candidate has no block IDs.
+func (c Candidate) CitationURL() string {
+	if len(c.BlockIDs) == 0 {
+		return c.URL
+	}
+	return notion.BlockURL(c.PageID, c.BlockIDs[0])
+}
+
+// Backend is a source of ranked candidates.
+type Backend interface {
+	// Name identifies the backend in results' provenance.
+	Name() string
+	// Retrieve returns up to limit candidates for query, best first.
+	Retrieve(ctx context.Context, query string, limit int) ([]Candidate, error)
+}
+
+// Source records where a backend ranked a result.
+type Source struct {
+	Backend string
+	// Rank is the candidate's position in the backend's results, from 1.
+	Rank  int
+	Score float64
+}
+
+// Result is a chunk or page returned by a Retriever.
+type Result struct {
+	Candidate
+	// Score is the fused score: the sum of weight/(k+rank) over the backends
+	// that returned the result.
+	Score   float64
+	Sources []Source
+}
+
+// Option configures a Retriever.
+type Option func(*Retriever)
+
+// WithBackend adds a backend whose reciprocal rank contributions are
+// multiplied by weight.
+func WithBackend(b Backend, weight float64) Option {
+	return func(r *Retriever) {
+		r.backends = append(r.backends, weighted{b, weight})
+	}
+}
+
+// WithRRFConstant sets k in the fusion score 1/(k+rank). Larger values
+// flatten the difference between top and lower ranks. The default is 60.
+func WithRRFConstant(k float64) Option {
+	return func(r *Retriever) {
+		if k > 0 {
+			r.k = k
+		}
+	}
+}
+
+// WithCandidates sets how many candidates are requested from each backend.
+// The default is 20.
+func WithCandidates(n int) Option {
+	return func(r *Retriever) {
+		if n > 0 {
+			r.candidates = n
+		}
+	}
+}
+
+// ErrPartial is wrapped by the error Retrieve returns along with its results
+// when some of the backends failed, so the results may be missing matches.
+var ErrPartial = errors.New("some retrieval backends failed")
+
+// Retriever fans queries out to its backends and fuses the results.
+type Retriever struct {
+	backends   []weighted
+	k          float64
+	candidates int
+}
+
+type weighted struct {
+	Backend
+	weight float64
+}
+
+// New returns a Retriever configured by opts.
+func New(opts ...Option) *Retriever {
+	r := &Retriever{k: 60, candidates: 20}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Retrieve queries every backend concurrently and returns up to limit fused
+// results, best first. A limit of 0 or less returns every result. When some
+// backends fail, the results of the others are returned with an error that
+// wraps ErrPartial and the failures; when all of them fail, no results are
+// returned.
+func (r *Retriever) Retrieve(ctx context.Context, query string, limit int) ([]Result, error) {
+	if len(r.backends) == 0 {
+		return nil, errors.New("retriever has no backends")
+	}
+	lists := make([][]Candidate, len(r.backends))
+	errs := make([]error, len(r.backends))
+	var wg sync.WaitGroup
+	for i, b := range r.backends {
+		wg.Add(1)
+		go func(i int, b weighted) {
+			defer wg.Done()
+			lists[i], errs[i] = b.Retrieve(ctx, query, r.candidates)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("%s: %w", b.Name(), errs[i])
+			}
+		}(i, b)
+	}
+	wg.Wait()
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed == len(r.backends) {
+		return nil, fmt.Errorf("failed to retrieve: %w", errors.Join(errs...))
+	}
+
+	results := r.fuse(lists)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("%w: %w", ErrPartial, errors.Join(errs...))
+	}
+	return results, nil
+}
+
+// fuse merges the backends' candidate lists with reciprocal rank fusion.
+func (r *Retriever) fuse(lists [][]Candidate) []Result {
+	byKey := make(map[string]*Result)
+	var order []string
+	for i, list := range lists {
+		b := r.backends[i]
+		seen := make(map[string]bool)
+		for rank, c := range list {
+			key := notion.NormalizeID(c.PageID)
+			if c.ChunkID != "" {
+				key += "#" + c.ChunkID
+			}
+			// A backend listing the same chunk twice only counts once.
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			res := byKey[key]
+			if res == nil {
+				res = &Result{Candidate: c}
+				byKey[key] = res
+				order = append(order, key)
+			}
+			res.Score += b.weight / (r.k + float64(rank+1))
+			res.Sources = append(res.Sources, Source{Backend: b.Name(), Rank: rank + 1, Score: c.Score})
+		}
+	}
+
+	// Fold whole-page results into the page's best chunk, if any backend
+	// returned chunks of it.
+	bestChunk := make(map[string]*Result)
+	for _, key := range order {
+		res := byKey[key]
+		page := notion.NormalizeID(res.PageID)
+		if res.ChunkID != "" && (bestChunk[page] == nil || res.Score > bestChunk[page].Score) {
+			bestChunk[page] = res
+		}
+	}
+	results := make([]Result, 0, len(order))
+	for _, key := range order {
+		res := byKey[key]
+		best := bestChunk[notion.NormalizeID(res.PageID)]
+		if res.ChunkID != "" || best == nil {
+			continue
+		}
+		best.Score += res.Score
+		best.Sources = append(best.Sources, res.Sources...)
+		if best.Title == "" {
+			best.Title = res.Title
+		}
+		if best.URL == "" {
+			best.URL = res.URL
+		}
+		delete(byKey, key)
+	}
+	for _, key := range order {
+		if res := byKey[key]; res != nil {
+			results = append(results, *res)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return len(results[i].Sources) > len(results[j].Sources)
+	})
+	return results
+}
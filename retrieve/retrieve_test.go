@@ -0,0 +1,120 @@
+package retrieve
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/fulltext"
+	"github.com/openai/notion-go-agents/internal/notiontest"
+	"github.com/openai/notion-go-agents/vector"
+)
+
+func fixed(name string, cands ...Candidate) Backend {
+	return BackendFunc{BackendName: name, Fn: func(ctx context.Context, query string, limit int) ([]Candidate, error) {
+		return cands, nil
+	}}
+}
+
+func summarize(results []Result) string {
+	var lines []string
+	for _, r := range results {
+		var sources []string
+		for _, s := range r.Sources {
+			sources = append(sources, s.Backend)
+		}
+		key := r.PageID
+		if r.ChunkID != "" {
+			key = r.ChunkID
+		}
+		lines = append(lines, key+"("+strings.Join(sources, ",")+")")
+	}
+	return strings.Join(lines, " ")
+}
+
+func TestFuse(t *testing.T) {
+	failing := BackendFunc{BackendName: "broken", Fn: func(ctx context.Context, query string, limit int) ([]Candidate, error) {
+		return nil, errors.New("unavailable")
+	}}
+	r := New(
+		WithBackend(fixed("pages", Candidate{PageID: "p1"}, Candidate{PageID: "p-2", Title: "Two"}, Candidate{PageID: "p1"}), 1),
+		WithBackend(fixed("chunks", Candidate{PageID: "p2", ChunkID: "p2#3"}, Candidate{PageID: "p3", ChunkID: "p3#0"}), 1),
+		WithBackend(failing, 1),
+	)
+	// The failing backend is reported with the others' results.
+	results, err := r.Retrieve(context.Background(), "q", 0)
+	if !errors.Is(err, ErrPartial) || !strings.Contains(err.Error(), "broken: unavailable") {
+		t.Fatalf("expected a partial failure, got %v", err)
+	}
+	if got := summarize(results); got != "p2#3(chunks,pages) p1(pages) p3#0(chunks)" {
+		t.Fatalf("unexpected results: %s", got)
+	}
+	if results[0].Title != "Two" || results[0].Sources[1].Rank != 2 {
+		t.Fatalf("expected the page result to be folded into its chunk: %+v", results[0])
+	}
+	if want := 1/61.0 + 1/62.0; math.Abs(results[0].Score-want) > 1e-12 {
+		t.Fatalf("unexpected fused score %f, want %f", results[0].Score, want)
+	}
+
+	// Weights change the order.
+	r = New(
+		WithBackend(fixed("a", Candidate{PageID: "x"}, Candidate{PageID: "y"}), 1),
+		WithBackend(fixed("b", Candidate{PageID: "y"}), 0.1),
+	)
+	if results, _ := r.Retrieve(context.Background(), "q", 1); summarize(results) != "y(a,b)" {
+		t.Fatalf("unexpected results: %s", summarize(results))
+	}
+	r = New(
+		WithBackend(fixed("a", Candidate{PageID: "x"}, Candidate{PageID: "y"}), 1),
+		WithBackend(fixed("b", Candidate{PageID: "y"}), 0),
+	)
+	if results, _ := r.Retrieve(context.Background(), "q", 1); summarize(results) != "x(a)" {
+		t.Fatalf("unexpected results: %s", summarize(results))
+	}
+
+	if results, err := New(WithBackend(failing, 1)).Retrieve(context.Background(), "q", 0); results != nil || errors.Is(err, ErrPartial) || !strings.Contains(err.Error(), "broken: unavailable") {
+		t.Fatalf("expected the backend error, got %v", err)
+	}
+}
+
+func TestRetrieve(t *testing.T) {
+	srv := notiontest.New(t)
+	srv.AddPage(notiontest.Page("keys", "Key rotation"))
+	srv.SetChildren("keys", notiontest.TextBlock("p1", "paragraph", "Rotate the API keys every quarter."))
+	srv.AddPage(notiontest.Page("lunch", "Office"))
+	srv.SetChildren("lunch", notiontest.TextBlock("p2", "paragraph", "The lunch menu changes weekly."))
+	client := notion.NewClient("secret", "", notion.WithHTTPClient(srv.HTTPClient()))
+	ctx := context.Background()
+
+	keyword := fulltext.NewIndex()
+	vectors := vector.NewIndex(vector.NewHashEmbedder(256))
+	for _, id := range []string{"keys", "lunch"} {
+		pc, err := notion.GetPageContent(ctx, client, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyword.Add(pc)
+		if err := vectors.AddPage(ctx, pc, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := New(
+		WithBackend(NotionSearch(client), 1),
+		WithBackend(Keyword(keyword), 1),
+		WithBackend(Vector(vectors), 1),
+	)
+	results, err := r.Retrieve(ctx, "rotation", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := summarize(results); got != "keys#0(vector,notion,keyword)" {
+		t.Fatalf("unexpected results: %s", got)
+	}
+	if results[0].Text != "Rotate the API keys every quarter." || results[0].URL == "" {
+		t.Fatalf("unexpected result: %+v", results[0])
+	}
+}
@@ -0,0 +1,196 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits keeping schema descriptions compact.
+const (
+	maxSchemaOptions     = 30
+	maxSampleValueLength = 80
+)
+
+// SchemaProperty describes a property of a database schema.
+type SchemaProperty struct {
+	Name string
+	// Type is the property type, such as "select" or "relation", which is
+	// also the key of its condition in a database query filter.
+	Type string
+	// Options are the option names of a select, multi-select or status
+	// property, in the database's order.
+	Options []string
+	// NumberFormat is the display format of a number property, such as
+	// "dollar" or "percent".
+	NumberFormat string
+	// RelationDatabaseID is the database a relation property links to.
+	RelationDatabaseID string
+	// Rollup describes a rollup property, such as "sum of Tasks.Points".
+	Rollup string
+	// FormulaType is the result type of a formula property: "string",
+	// "number", "boolean" or "date". The schema does not record it, so it is
+	// only known once a row's value has been seen.
+	FormulaType string
+}
+
+// DatabaseSchema returns the properties of a database, the title property
+// first and the others by name. Pass rows of the database to learn the result
+// types of its formulas.
+func DatabaseSchema(db *NotionDatabase, rows ...*NotionPage) []SchemaProperty {
+	var props []SchemaProperty
+	for _, name := range schemaPropertyNames(db.Properties) {
+		prop, _ := db.Properties[name].(map[string]any)
+		typ, _ := prop["type"].(string)
+		p := SchemaProperty{Name: name, Type: typ}
+		config, _ := prop[typ].(map[string]any)
+		switch typ {
+		case "select", "multi_select", "status":
+			options, _ := config["options"].([]any)
+			for _, o := range options {
+				om, _ := o.(map[string]any)
+				if name, _ := om["name"].(string); name != "" {
+					p.Options = append(p.Options, name)
+				}
+			}
+		case "number":
+			if format, _ := config["format"].(string); format != "number" {
+				p.NumberFormat = format
+			}
+		case "relation":
+			p.RelationDatabaseID, _ = config["database_id"].(string)
+		case "rollup":
+			relation, _ := config["relation_property_name"].(string)
+			target, _ := config["rollup_property_name"].(string)
+			function, _ := config["function"].(string)
+			if function != "" && relation != "" {
+				p.Rollup = strings.ReplaceAll(function, "_", " ") + " of " + relation + "." + target
+			}
+		case "formula":
+			p.FormulaType = formulaType(name, rows)
+		}
+		props = append(props, p)
+	}
+	return props
+}
+
+// formulaType returns the result type of a formula property from the first
+// row that has a value for it.
+func formulaType(name string, rows []*NotionPage) string {
+	for _, pg := range rows {
+		prop, _ := pg.Properties[name].(map[string]any)
+		value, _ := prop["formula"].(map[string]any)
+		if t, _ := value["type"].(string); t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+// DescribeDatabaseSchema fetches a database, given by its ID or URL, and up
+// to sampleRows of its rows, and describes it with DescribeSchema. The
+// titles of the databases that relation properties link to are looked up;
+// ones that cannot be fetched are left out.
+func DescribeDatabaseSchema(ctx context.Context, client *Client, databaseID string, sampleRows int) (string, error) {
+	db, err := client.GetDatabase(ctx, databaseID)
+	if err != nil {
+		return "", err
+	}
+	var rows []*NotionPage
+	if sampleRows > 0 {
+		res, err := client.QueryDatabase(ctx, databaseID, NotionDatabaseQueryRequest{PageSize: min(sampleRows, 100)})
+		if err != nil {
+			return "", err
+		}
+		for _, raw := range res.Results {
+			var pg NotionPage
+			if err := json.Unmarshal(raw, &pg); err == nil && pg.Object == "page" {
+				rows = append(rows, &pg)
+			}
+		}
+	}
+	titles := make(map[string]string)
+	for _, p := range DatabaseSchema(db) {
+		if id := p.RelationDatabaseID; id != "" && titles[id] == "" {
+			if target, err := client.GetDatabase(ctx, id); err == nil {
+				titles[id] = PlainText(target.Title)
+			}
+		}
+	}
+	return DescribeSchema(db, rows, titles), nil
+}
+
+// DescribeSchema renders a compact, deterministic description of a database
+// for LLM prompts: its title and ID, each property with its type and
+// options, relation targets, rollups and formula result types, how to filter
+// on a property, and the given sample rows. relationTitles maps database IDs
+// to titles for naming relation targets and may be nil.
+func DescribeSchema(db *NotionDatabase, rows []*NotionPage, relationTitles map[string]string) string {
+	var b strings.Builder
+	title := PlainText(db.Title)
+	if title == "" {
+		title = "Untitled"
+	}
+	fmt.Fprintf(&b, "Database: %s (id %s)\n", title, db.ID)
+	if desc := PlainText(db.Description); desc != "" {
+		fmt.Fprintf(&b, "Description: %s\n", desc)
+	}
+	props := DatabaseSchema(db, rows...)
+	b.WriteString("Properties:\n")
+	for _, p := range props {
+		fmt.Fprintf(&b, "- %s (%s", p.Name, p.Type)
+		switch {
+		case p.NumberFormat != "":
+			b.WriteString(", " + p.NumberFormat)
+		case p.RelationDatabaseID != "":
+			b.WriteString(" → ")
+			if t := relationTitles[p.RelationDatabaseID]; t != "" {
+				b.WriteString(t + " ")
+			}
+			b.WriteString("database " + p.RelationDatabaseID)
+		case p.Rollup != "":
+			b.WriteString(": " + p.Rollup)
+		case p.FormulaType != "":
+			b.WriteString(" → " + p.FormulaType)
+		}
+		b.WriteString(")")
+		if len(p.Options) > 0 {
+			options := p.Options
+			if len(options) > maxSchemaOptions {
+				options = options[:maxSchemaOptions]
+			}
+			b.WriteString(": " + strings.Join(options, ", "))
+			if n := len(p.Options) - len(options); n > 0 {
+				fmt.Fprintf(&b, ", … (%d more)", n)
+			}
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(`Filter on a property with {"property": "<name>", "<type>": {<condition>}}`)
+	if len(props) > 0 && props[0].Type == "title" {
+		fmt.Fprintf(&b, `, such as {"property": %q, "title": {"contains": "..."}}`, props[0].Name)
+	}
+	b.WriteString(".\n")
+	if len(rows) == 0 {
+		return b.String()
+	}
+	b.WriteString("Sample rows:\n")
+	for i, pg := range rows {
+		var cells []string
+		for _, p := range props {
+			prop, _ := pg.Properties[p.Name].(map[string]any)
+			text := strings.Join(strings.Fields(PropertyText(prop)), " ")
+			if text == "" {
+				continue
+			}
+			if utf8.RuneCountInString(text) > maxSampleValueLength {
+				text = string([]rune(text)[:maxSampleValueLength-1]) + "…"
+			}
+			cells = append(cells, p.Name+": "+text)
+		}
+		fmt.Fprintf(&b, "%d. %s\n", i+1, strings.Join(cells, "; "))
+	}
+	return b.String()
+}
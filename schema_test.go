@@ -0,0 +1,63 @@
+package notion
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openai/notion-go-agents/internal/notiontest"
+)
+
+func TestDescribeDatabaseSchema(t *testing.T) {
+	srv := notiontest.New(t)
+	srv.AddDatabase(notiontest.Database("projects", "Projects", map[string]any{}))
+	srv.AddDatabase(notiontest.Database("tasks", "Tasks", map[string]any{
+		"Name": map[string]any{"type": "title", "title": map[string]any{}},
+		"Status": map[string]any{"type": "select", "select": map[string]any{"options": []any{
+			map[string]any{"name": "Open"}, map[string]any{"name": "Done"},
+		}}},
+		"Budget":  map[string]any{"type": "number", "number": map[string]any{"format": "dollar"}},
+		"Project": map[string]any{"type": "relation", "relation": map[string]any{"database_id": "projects"}},
+		"Spent": map[string]any{"type": "rollup", "rollup": map[string]any{
+			"relation_property_name": "Project", "rollup_property_name": "Cost", "function": "sum",
+		}},
+		"Days": map[string]any{"type": "formula", "formula": map[string]any{"expression": "1"}},
+	}),
+		notiontest.Row("t1", map[string]any{
+			"Name":   notiontest.TitleProperty("Write docs"),
+			"Status": notiontest.SelectProperty("Open"),
+			"Days":   map[string]any{"type": "formula", "formula": map[string]any{"type": "number", "number": 3}},
+		}),
+		notiontest.Row("t2", map[string]any{"Name": notiontest.TitleProperty("Ship")}),
+	)
+	client := NewClient("secret", "", WithHTTPClient(srv.HTTPClient()))
+
+	got, err := DescribeDatabaseSchema(context.Background(), client, "tasks", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Database: Tasks (id tasks)
+Properties:
+- Name (title)
+- Budget (number, dollar)
+- Days (formula → number)
+- Project (relation → Projects database projects)
+- Spent (rollup: sum of Project.Cost)
+- Status (select): Open, Done
+Filter on a property with {"property": "<name>", "<type>": {<condition>}}, such as {"property": "Name", "title": {"contains": "..."}}.
+Sample rows:
+1. Name: Write docs; Days: 3; Status: Open
+2. Name: Ship
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	got, err = DescribeDatabaseSchema(context.Background(), client, "tasks", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "Sample rows") || !strings.Contains(got, "- Days (formula)\n") {
+		t.Errorf("without samples got:\n%s", got)
+	}
+}
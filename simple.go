@@ -1,6 +1,9 @@
 package notion
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 // FindNotionPage searches workspace pages for the given query using a new client
 // created with the provided apiKey. It returns the first matching page as
@@ -10,10 +13,27 @@ func FindNotionPage(ctx context.Context, apiKey, query string) (*PageContent, er
 	return FindPageByQuery(ctx, client, query)
 }
 
-// SearchNotionDB queries a Notion database for pages whose title contains the
-// query. The client is constructed from apiKey. Limit controls the maximum number
-// of pages returned (0 means no limit).
+// SearchNotionDB queries a Notion database, given by its ID or URL, for pages
+// whose title contains the query; an empty query matches every page. The
+// client is constructed from apiKey. Limit controls the maximum number of
+// pages returned (0 means no limit).
 func SearchNotionDB(ctx context.Context, apiKey, databaseID, query string, limit int) ([]PageContent, error) {
 	client := NewClient(apiKey, "")
-	return SearchNotionDatabase(ctx, client, databaseID, query, limit)
+	var req NotionDatabaseQueryRequest
+	if query != "" {
+		db, err := client.GetDatabase(ctx, databaseID)
+		if err != nil {
+			return nil, err
+		}
+		for name, v := range db.Properties {
+			if prop, _ := v.(map[string]any); prop["type"] == "title" {
+				req.Filter = map[string]any{"property": name, "title": map[string]any{"contains": query}}
+				break
+			}
+		}
+		if req.Filter == nil {
+			return nil, fmt.Errorf("database %s has no title property to search", databaseID)
+		}
+	}
+	return SearchNotionDatabase(ctx, client, databaseID, req, limit)
 }
@@ -0,0 +1,159 @@
+package notion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SourceSpan maps a range of rendered Markdown to the block it was rendered
+// from.
+type SourceSpan struct {
+	BlockID string
+	// Start and End are byte offsets into the Markdown; End is exclusive.
+	Start, End int
+	// StartLine and EndLine are the first and last lines of the range,
+	// counted from 1.
+	StartLine, EndLine int
+}
+
+// SourceMap maps rendered Markdown back to the blocks of a page, for citing
+// the exact block a quote comes from. Spans are in document order and do not
+// overlap. Each top-level block gets one span covering its nested children;
+// the blocks inside columns and synced blocks get spans of their own.
+type SourceMap []SourceSpan
+
+// BlockAt returns the ID of the block rendered at the given byte offset, or
+// "" when the offset falls between blocks or outside the Markdown.
+func (m SourceMap) BlockAt(offset int) string {
+	i := sort.Search(len(m), func(i int) bool { return m[i].End > offset })
+	if i < len(m) && m[i].Start <= offset {
+		return m[i].BlockID
+	}
+	return ""
+}
+
+// BlockAtLine returns the ID of the block rendered on the given line,
+// counted from 1, or "" when no block is.
+func (m SourceMap) BlockAtLine(line int) string {
+	i := sort.Search(len(m), func(i int) bool { return m[i].EndLine >= line })
+	if i < len(m) && m[i].StartLine <= line {
+		return m[i].BlockID
+	}
+	return ""
+}
+
+// Blocks returns the IDs of the blocks whose Markdown overlaps the byte range
+// [start, end), in order.
+func (m SourceMap) Blocks(start, end int) []string {
+	var ids []string
+	for _, span := range m {
+		if span.Start < end && span.End > start {
+			ids = append(ids, span.BlockID)
+		}
+	}
+	return ids
+}
+
+// WithSourceMap makes ConvertPage fill in PageContent.SourceMap. Pages are
+// then fetched in full before they are rendered.
+func WithSourceMap() ConverterOption {
+	return func(c *NotionMarkdownConverter) {
+		c.sourceMap = true
+	}
+}
+
+// BlockURL returns a link to a block within a page, such as
+// "https://www.notion.so/<page>#<block>", which opens the page scrolled to
+// the block. Without a block ID it returns the page's URL.
+func BlockURL(pageID, blockID string) string {
+	if blockID == "" {
+		return notionURL(pageID)
+	}
+	return notionURL(pageID) + "#" + strings.ReplaceAll(blockID, "-", "")
+}
+
+// RenderMarkdownWithSourceMap renders blocks like RenderMarkdown and also
+// returns the source map of the result.
+func (c *NotionMarkdownConverter) RenderMarkdownWithSourceMap(nodes []BlockNode) (string, SourceMap) {
+	var spans SourceMap
+	full := c.renderBlocksMapped(nodes, renderState{}, &spans)
+	md := strings.TrimSpace(full)
+	lead := len(full) - len(strings.TrimLeftFunc(full, unicode.IsSpace))
+	for i := range spans {
+		spans[i].Start = max(spans[i].Start-lead, 0)
+		spans[i].End = min(spans[i].End-lead, len(md))
+		spans[i].StartLine = strings.Count(md[:spans[i].Start], "\n") + 1
+		spans[i].EndLine = strings.Count(md[:spans[i].End], "\n") + 1
+	}
+	return md, spans
+}
+
+// renderBlocksMapped renders sibling blocks like renderBlocksToMarkdown,
+// appending the span of each block to spans.
+func (c *NotionMarkdownConverter) renderBlocksMapped(nodes []BlockNode, st renderState, spans *SourceMap) string {
+	var b strings.Builder
+	var seq blockSequence
+	for _, node := range nodes {
+		blockType, _ := node.Block["type"].(string)
+		st.number = seq.nextNumber()
+		var md string
+		var inner SourceMap
+		flattened := blockType == "column" || blockType == "synced_block" || (blockType == "column_list" && c.columns == ColumnsFlatten)
+		if flattened {
+			md = c.renderBlocksMapped(node.Children, st, &inner)
+		} else {
+			md = c.renderBlockToMarkdown(node, st)
+		}
+		if md == "" {
+			continue
+		}
+		sep := seq.advance(c.listKind(blockType))
+		start := b.Len() + len(sep)
+		b.WriteString(sep + md)
+		if flattened {
+			for _, span := range inner {
+				span.Start += start
+				span.End += start
+				*spans = append(*spans, span)
+			}
+			continue
+		}
+		id, _ := node.Block["id"].(string)
+		*spans = append(*spans, SourceSpan{BlockID: id, Start: start, End: start + len(strings.TrimRightFunc(md, unicode.IsSpace))})
+	}
+	return b.String()
+}
+
+// convertPageMapped renders a page like convertPage, from its full block
+// tree, and returns its source map.
+func (c *NotionMarkdownConverter) convertPageMapped(ctx context.Context, pg *NotionPage) (string, SourceMap, error) {
+	var nodes []BlockNode
+	var err error
+	if cache := c.cache(); cache != nil && pg.LastEditedTime != "" {
+		nodes, err = c.cachedBlockTree(ctx, cache, pg.ID, pg.LastEditedTime)
+	} else if nodes, err = c.GetBlockTree(ctx, pg.ID); err != nil {
+		err = fmt.Errorf("failed to get block tree: %w", err)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	md, spans := c.RenderMarkdownWithSourceMap(nodes)
+	if md == "" {
+		md = "(no textual content)"
+	}
+	if c.frontMatter {
+		prefix := FrontMatter(pg) + "\n"
+		lines := strings.Count(prefix, "\n")
+		for i := range spans {
+			spans[i].Start += len(prefix)
+			spans[i].End += len(prefix)
+			spans[i].StartLine += lines
+			spans[i].EndLine += lines
+		}
+		md = prefix + md
+	}
+	return md, spans, nil
+}
@@ -0,0 +1,103 @@
+package notion
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openai/notion-go-agents/internal/notiontest"
+)
+
+func TestRenderMarkdownWithSourceMap(t *testing.T) {
+	nodes := []BlockNode{
+		{Block: notiontest.TextBlock("h", "heading_2", "Steps")},
+		{Block: notiontest.TextBlock("b1", "bulleted_list_item", "one"), Children: []BlockNode{
+			{Block: notiontest.TextBlock("b2", "bulleted_list_item", "nested")},
+		}},
+		{Block: notiontest.TextBlock("b3", "bulleted_list_item", "two")},
+		{Block: notiontest.TextBlock("empty", "paragraph", "")},
+		{Block: notiontest.Block("cols", "column_list", nil), Children: []BlockNode{
+			{Block: notiontest.Block("c1", "column", nil), Children: []BlockNode{
+				{Block: notiontest.TextBlock("left", "paragraph", "Left")},
+			}},
+			{Block: notiontest.Block("c2", "column", nil), Children: []BlockNode{
+				{Block: notiontest.TextBlock("right", "paragraph", "Right")},
+			}},
+		}},
+	}
+	conv := NewNotionMarkdownConverter(nil, WithColumns(ColumnsFlatten))
+	md, sm := conv.RenderMarkdownWithSourceMap(nodes)
+	if want := conv.RenderMarkdown(nodes); md != want {
+		t.Fatalf("Markdown differs from RenderMarkdown:\n%s\nwant:\n%s", md, want)
+	}
+	want := []struct {
+		id, text   string
+		start, end int
+	}{
+		{"h", "## Steps", 1, 1},
+		{"b1", "- one\n  - nested", 3, 4},
+		{"b3", "- two", 5, 5},
+		{"left", "Left", 7, 7},
+		{"right", "Right", 9, 9},
+	}
+	if len(sm) != len(want) {
+		t.Fatalf("expected %d spans, got %+v", len(want), sm)
+	}
+	for i, w := range want {
+		span := sm[i]
+		if span.BlockID != w.id || md[span.Start:span.End] != w.text || span.StartLine != w.start || span.EndLine != w.end {
+			t.Errorf("span %d: got %+v (%q), want %s %q lines %d-%d", i, span, md[span.Start:span.End], w.id, w.text, w.start, w.end)
+		}
+	}
+	if id := sm.BlockAt(strings.Index(md, "nested")); id != "b1" {
+		t.Errorf("BlockAt: got %q", id)
+	}
+	if id := sm.BlockAt(strings.Index(md, "\n\n")); id != "" {
+		t.Errorf("BlockAt between blocks: got %q", id)
+	}
+	if id := sm.BlockAtLine(9); id != "right" {
+		t.Errorf("BlockAtLine: got %q", id)
+	}
+	if ids := sm.Blocks(0, strings.Index(md, "two")); strings.Join(ids, ",") != "h,b1,b3" {
+		t.Errorf("Blocks: got %v", ids)
+	}
+}
+
+func TestConvertPageWithSourceMap(t *testing.T) {
+	srv := notiontest.New(t)
+	srv.AddPage(notiontest.Page("p", "Runbook"))
+	srv.SetChildren("p",
+		notiontest.TextBlock("a", "paragraph", "First."),
+		notiontest.TextBlock("b", "paragraph", "Second."),
+	)
+	client := NewClient("secret", "", WithHTTPClient(srv.HTTPClient()))
+	pg, err := client.GetPage(context.Background(), "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc, err := ConvertPage(context.Background(), client, pg, WithSourceMap(), WithFrontMatter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := ConvertPage(context.Background(), client, pg, WithFrontMatter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pc.Markdown != plain.Markdown || plain.SourceMap != nil {
+		t.Fatalf("source map changed the output:\n%s\nwant:\n%s", pc.Markdown, plain.Markdown)
+	}
+	span := pc.SourceMap[1]
+	lines := strings.Split(pc.Markdown, "\n")
+	if span.BlockID != "b" || pc.Markdown[span.Start:span.End] != "Second." || lines[span.StartLine-1] != "Second." {
+		t.Fatalf("unexpected span %+v in:\n%s", span, pc.Markdown)
+	}
+}
+
+func TestBlockURL(t *testing.T) {
+	if got := BlockURL("1a2b3c4d-5e6f-47a8-b9c0-d1e2f3a4b5c6", "0f0f0f0f-0f0f-0f0f-0f0f-0f0f0f0f0f0f"); got != "https://www.notion.so/1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6#0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f" {
+		t.Fatalf("unexpected URL %q", got)
+	}
+	if got := BlockURL("1a2b3c4d-5e6f-47a8-b9c0-d1e2f3a4b5c6", ""); got != "https://www.notion.so/1a2b3c4d5e6f47a8b9c0d1e2f3a4b5c6" {
+		t.Fatalf("unexpected URL %q", got)
+	}
+}
@@ -0,0 +1,119 @@
+package notion
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithAlignedTables pads table cells so that columns line up in the Markdown
+// source, and right-aligns columns that contain only numbers. Columns are
+// padded to at most maxWidth characters (0 means no limit); longer cells are
+// kept whole and simply break the alignment.
+func WithAlignedTables(maxWidth int) ConverterOption {
+	return func(c *NotionMarkdownConverter) {
+		c.alignTables = true
+		c.maxTableColumnWidth = maxWidth
+	}
+}
+
+// WithWideTableFallback renders tables and child databases with more than
+// maxColumns columns as lists of key/value pairs, one list item per row,
+// which language models read more reliably than very wide tables.
+func WithWideTableFallback(maxColumns int) ConverterOption {
+	return func(c *NotionMarkdownConverter) {
+		c.wideTableColumns = maxColumns
+	}
+}
+
+var numericCell = regexp.MustCompile(`^[-+]?[$€£¥]?[0-9][0-9,]*(\.[0-9]+)?%?$`)
+
+// alignedMarkdownTable renders a table with padded cells.
+func alignedMarkdownTable(header []string, body [][]string, maxWidth int) string {
+	widths := make([]int, len(header))
+	numeric := make([]bool, len(header))
+	for i, h := range header {
+		widths[i] = utf8.RuneCountInString(h)
+		if widths[i] < 3 {
+			widths[i] = 3
+		}
+		numeric[i] = len(body) > 0
+	}
+	for _, row := range body {
+		for i, cell := range row {
+			if w := utf8.RuneCountInString(cell); w > widths[i] {
+				widths[i] = w
+			}
+			if cell != "" && !numericCell.MatchString(cell) {
+				numeric[i] = false
+			}
+		}
+	}
+	if maxWidth > 0 {
+		for i := range widths {
+			if widths[i] > maxWidth {
+				widths[i] = maxWidth
+			}
+		}
+	}
+	line := func(cells []string) string {
+		padded := make([]string, len(cells))
+		for i, cell := range cells {
+			padding := widths[i] - utf8.RuneCountInString(cell)
+			if padding < 0 {
+				padding = 0
+			}
+			if numeric[i] {
+				padded[i] = strings.Repeat(" ", padding) + cell
+			} else {
+				padded[i] = cell + strings.Repeat(" ", padding)
+			}
+		}
+		return "| " + strings.Join(padded, " | ") + " |"
+	}
+	sep := make([]string, len(header))
+	for i, w := range widths {
+		if numeric[i] {
+			sep[i] = strings.Repeat("-", w-1) + ":"
+		} else {
+			sep[i] = strings.Repeat("-", w)
+		}
+	}
+	lines := []string{line(header), "| " + strings.Join(sep, " | ") + " |"}
+	for _, row := range body {
+		lines = append(lines, line(row))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// keyValueTable renders each row as a list item holding "key: value" pairs.
+// Keys come from header, or are numbered when header is nil. When labelFirst
+// is set, the first cell of each row labels its item instead of being listed
+// as a pair. Empty cells are skipped.
+func keyValueTable(header []string, body [][]string, labelFirst bool) string {
+	var items []string
+	for r, row := range body {
+		label := "Row " + strconv.Itoa(r+1)
+		start := 0
+		if labelFirst && len(row) > 0 {
+			if row[0] != "" {
+				label = row[0]
+			}
+			start = 1
+		}
+		lines := []string{"- **" + label + "**"}
+		for i := start; i < len(row); i++ {
+			if row[i] == "" {
+				continue
+			}
+			key := "Column " + strconv.Itoa(i+1)
+			if i < len(header) && header[i] != "" {
+				key = header[i]
+			}
+			lines = append(lines, "  - "+key+": "+row[i])
+		}
+		items = append(items, strings.Join(lines, "\n"))
+	}
+	return strings.Join(items, "\n")
+}
@@ -0,0 +1,166 @@
+package notion
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RenderText renders a block tree fetched with GetBlockTree as plain text,
+// without Markdown syntax: list items keep a "-", number or checkbox marker,
+// nested blocks are indented by two spaces, and table cells are separated by
+// " | ".
+func (c *NotionMarkdownConverter) RenderText(nodes []BlockNode) string {
+	return strings.TrimSpace(c.renderTextBlocks(nodes))
+}
+
+func (c *NotionMarkdownConverter) renderTextBlocks(nodes []BlockNode) string {
+	var b strings.Builder
+	prevList := ""
+	number := 0
+	for _, node := range nodes {
+		blockType, _ := node.Block["type"].(string)
+		list := htmlList(blockType)
+		if list == "ol" {
+			if prevList == "ol" {
+				number++
+			} else {
+				number = 1
+			}
+		}
+		text := c.blockTextPlain(node, number)
+		if text == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			if list != "" && list == prevList {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		b.WriteString(text)
+		prevList = list
+	}
+	return b.String()
+}
+
+// blockTextPlain renders a block and its children as plain text. number is
+// the position of a numbered list item.
+func (c *NotionMarkdownConverter) blockTextPlain(node BlockNode, number int) string {
+	block := node.Block
+	blockType, _ := block["type"].(string)
+	data, _ := block[blockType].(map[string]any)
+	text := c.richTextPlain(richTextOf(data))
+	switch blockType {
+	case "bulleted_list_item":
+		text = "- " + text
+	case "numbered_list_item":
+		text = strconv.Itoa(number) + ". " + text
+	case "to_do":
+		if checked, _ := data["checked"].(bool); checked {
+			text = "[x] " + text
+		} else {
+			text = "[ ] " + text
+		}
+	case "code":
+		rt, _ := data["rich_text"].([]any)
+		text = strings.Trim(rawRichText(rt), "\n")
+	case "equation":
+		text, _ = data["expression"].(string)
+	case "bookmark", "embed", "link_preview":
+		text, _ = data["url"].(string)
+	case "link_to_page":
+		linkType, _ := data["type"].(string)
+		if id, _ := data[linkType].(string); id != "" {
+			text = c.pageTitle(id, "")
+		}
+	case "image", "video", "audio", "pdf", "file":
+		text = PlainText(asArray(data["caption"]))
+	case "child_page", "child_database":
+		text, _ = data["title"].(string)
+		if node.database != nil && len(node.database.rows) > 0 {
+			rows := []string{strings.Join(node.database.columns, " | ")}
+			for _, row := range node.database.rows {
+				cells := make([]string, len(row))
+				for i, cell := range row {
+					cells[i] = strings.ReplaceAll(markdownCellText(cell), "\n", " ")
+				}
+				rows = append(rows, strings.Join(cells, " | "))
+			}
+			text = strings.TrimSpace(text + "\n\n" + strings.Join(rows, "\n"))
+		}
+	case "table":
+		var rows []string
+		for _, child := range node.Children {
+			row, _ := child.Block["table_row"].(map[string]any)
+			cells := asArray(row["cells"])
+			out := make([]string, len(cells))
+			for i, cell := range cells {
+				out[i] = strings.ReplaceAll(c.richTextPlain(asArray(cell)), "\n", " ")
+			}
+			rows = append(rows, strings.Join(out, " | "))
+		}
+		return strings.Join(rows, "\n")
+	}
+	text = strings.TrimRight(text, " ")
+
+	if len(node.Children) == 0 || blockType == "child_database" {
+		return text
+	}
+	children := c.renderTextBlocks(node.Children)
+	switch {
+	case children == "":
+		return text
+	case blockType == "column_list" || blockType == "column" || blockType == "synced_block":
+		return children
+	case blockType == "child_page":
+		// Inlined child pages are sections of the page, not nested in it.
+		return strings.TrimSpace(text + "\n\n" + children)
+	case text == "":
+		return indentLines(children, "  ", true)
+	}
+	sep := "\n\n"
+	if htmlList(blockType) != "" && htmlList(firstBlockType(node.Children)) != "" {
+		sep = "\n"
+	}
+	return text + sep + strings.TrimRight(indentLines(children, "  ", true), " ")
+}
+
+// richTextPlain concatenates rich text without formatting, resolving
+// mentions to the titles and names they refer to.
+func (c *NotionMarkdownConverter) richTextPlain(richText []any) string {
+	var b strings.Builder
+	for _, item := range richText {
+		itemMap, _ := item.(map[string]any)
+		itemType, _ := itemMap["type"].(string)
+		if itemType == "equation" {
+			equation, _ := itemMap["equation"].(map[string]any)
+			expr, _ := equation["expression"].(string)
+			b.WriteString(expr)
+			continue
+		}
+		text, _ := itemMap["plain_text"].(string)
+		if itemType == "mention" {
+			text, _ = c.mentionToText(itemMap, text, "")
+		}
+		b.WriteString(text)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// markdownCellText turns a table cell escaped for Markdown back into text.
+func markdownCellText(cell string) string {
+	var b strings.Builder
+	for i := 0; i < len(cell); i++ {
+		if cell[i] == '\\' && i+1 < len(cell) && isASCIIPunct(rune(cell[i+1])) {
+			i++
+		}
+		b.WriteByte(cell[i])
+	}
+	return strings.ReplaceAll(b.String(), "<br>", "\n")
+}
+
+func asArray(v any) []any {
+	a, _ := v.([]any)
+	return a
+}
@@ -0,0 +1,26 @@
+package notion
+
+import "testing"
+
+func TestRenderText(t *testing.T) {
+	got := NewNotionMarkdownConverter(nil).RenderText(renderFixture())
+	want := `Title <1>
+
+Some bold and site
+
+- one
+  - nested
+
+1. first
+2. second
+
+[x] done
+
+a < b
+
+K | V
+a | 1`
+	if got != want {
+		t.Fatalf("unexpected text:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRichTextChars is the longest text the API accepts in one rich text item.
+const maxRichTextChars = 2000
+
+var numberedItem = regexp.MustCompile(`^\d+[.)] `)
+
+// markdownBlocks turns the Markdown a model writes into Notion blocks:
+// headings, bulleted, numbered and to-do list items, quotes, dividers, fenced
+// code and paragraphs, which are separated by blank lines. Text is kept as
+// written, inline formatting included, and nesting is flattened.
+func markdownBlocks(md string) []map[string]any {
+	var blocks []map[string]any
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			blocks = append(blocks, textBlock("paragraph", strings.Join(para, "\n")))
+			para = nil
+		}
+	}
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "```"):
+			flush()
+			language := strings.TrimSpace(strings.TrimPrefix(line, "```"))
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			b := textBlock("code", strings.Join(code, "\n"))
+			if language == "" {
+				language = "plain text"
+			}
+			b["code"].(map[string]any)["language"] = language
+			blocks = append(blocks, b)
+		case line == "---" || line == "***":
+			flush()
+			blocks = append(blocks, map[string]any{"type": "divider", "divider": map[string]any{}})
+		case strings.HasPrefix(line, "# "), strings.HasPrefix(line, "## "), strings.HasPrefix(line, "### "):
+			flush()
+			level := strings.Index(line, " ")
+			blocks = append(blocks, textBlock("heading_"+string(rune('0'+level)), line[level+1:]))
+		case strings.HasPrefix(line, "- [ ] "), strings.HasPrefix(line, "- [x] "), strings.HasPrefix(line, "- [X] "):
+			flush()
+			b := textBlock("to_do", line[6:])
+			b["to_do"].(map[string]any)["checked"] = line[3] != ' '
+			blocks = append(blocks, b)
+		case strings.HasPrefix(line, "- "), strings.HasPrefix(line, "* "):
+			flush()
+			blocks = append(blocks, textBlock("bulleted_list_item", line[2:]))
+		case numberedItem.MatchString(line):
+			flush()
+			blocks = append(blocks, textBlock("numbered_list_item", line[len(numberedItem.FindString(line)):]))
+		case strings.HasPrefix(line, ">"):
+			flush()
+			blocks = append(blocks, textBlock("quote", strings.TrimSpace(line[1:])))
+		default:
+			para = append(para, line)
+		}
+	}
+	flush()
+	return blocks
+}
+
+func textBlock(blockType, text string) map[string]any {
+	return map[string]any{
+		"type":    blockType,
+		blockType: map[string]any{"rich_text": richText(text)},
+	}
+}
+
+// richText returns text as rich text items within the API's length limit.
+func richText(text string) []any {
+	items := []any{}
+	for text != "" {
+		n := len(text)
+		if utf8.RuneCountInString(text) > maxRichTextChars {
+			n = len(string([]rune(text)[:maxRichTextChars]))
+		}
+		items = append(items, map[string]any{"type": "text", "text": map[string]any{"content": text[:n]}})
+		text = text[n:]
+	}
+	return items
+}
@@ -0,0 +1,431 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/retrieve"
+)
+
+// Limits keeping results small enough for a model's context.
+const (
+	defaultSearchLimit = 5
+	maxSearchLimit     = 20
+	defaultRowLimit    = 10
+	maxRowLimit        = 100
+	maxSnippetChars    = 500
+	maxPropertyChars   = 300
+	sampleRows         = 3
+)
+
+type searchArgs struct {
+	Query  string `json:"query"`
+	Limit  int    `json:"limit"`
+	Filter string `json:"filter"`
+}
+
+type searchResult struct {
+	ID             string   `json:"id"`
+	Object         string   `json:"object"`
+	Title          string   `json:"title"`
+	URL            string   `json:"url"`
+	LastEditedTime string   `json:"last_edited_time,omitempty"`
+	Breadcrumb     []string `json:"breadcrumb,omitempty"`
+	Snippet        string   `json:"snippet,omitempty"`
+	// CitationURL links to the block the snippet was taken from.
+	CitationURL string `json:"citation_url,omitempty"`
+}
+
+func (t *Toolset) searchTool() Tool {
+	return Tool{
+		Name:        "search_notion",
+		Description: "Search the Notion workspace for pages and databases. Returns IDs, titles and URLs; use get_page to read a page.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"query": map[string]any{"type": "string", "description": "Words to search for."},
+				"limit": map[string]any{"type": "integer", "minimum": 1, "maximum": maxSearchLimit, "description": fmt.Sprintf("Maximum number of results. Defaults to %d.", defaultSearchLimit)},
+				"filter": map[string]any{
+					"type":        "string",
+					"enum":        []any{"page", "database"},
+					"description": "Only return pages or only databases.",
+				},
+			},
+			"required":             []any{"query"},
+			"additionalProperties": false,
+		},
+		handler: t.search,
+	}
+}
+
+func (t *Toolset) search(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args searchArgs
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		return nil, invalid("query is required")
+	}
+	if args.Filter != "" && args.Filter != "page" && args.Filter != "database" {
+		return nil, invalid("filter must be \"page\" or \"database\"")
+	}
+	limit := clamp(args.Limit, defaultSearchLimit, maxSearchLimit)
+
+	results := []searchResult{}
+	if t.retriever != nil && args.Filter != "database" {
+		found, err := t.retriever.Retrieve(ctx, args.Query, limit)
+		if err != nil && !errors.Is(err, retrieve.ErrPartial) {
+			return nil, err
+		}
+		for _, r := range found {
+			res := searchResult{
+				ID:         r.PageID,
+				Object:     "page",
+				Title:      r.Title,
+				URL:        r.URL,
+				Breadcrumb: r.Breadcrumb,
+				Snippet:    truncate(r.Text, maxSnippetChars),
+			}
+			if len(r.BlockIDs) > 0 {
+				res.CitationURL = r.CitationURL()
+			}
+			results = append(results, res)
+		}
+		out := map[string]any{"results": results}
+		if err != nil {
+			// Say so when some matches may be missing.
+			out["warning"] = err.Error()
+		}
+		return out, nil
+	}
+
+	req := notion.NotionSearchRequest{Query: args.Query, PageSize: limit}
+	if args.Filter != "" {
+		req.Filter = &notion.NotionObjFilter{Property: "object", Value: args.Filter}
+	}
+	sr, err := t.client.Search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, raw := range sr.Results {
+		var ref notion.NotionPageRef
+		if err := json.Unmarshal(raw, &ref); err != nil {
+			continue
+		}
+		switch ref.Object {
+		case "page":
+			var pg notion.NotionPage
+			if err := json.Unmarshal(raw, &pg); err != nil {
+				continue
+			}
+			results = append(results, searchResult{ID: pg.ID, Object: "page", Title: notion.ExtractNotionTitle(pg.Properties), URL: pg.URL, LastEditedTime: pg.LastEditedTime})
+		case "database":
+			var db notion.NotionDatabase
+			if err := json.Unmarshal(raw, &db); err != nil {
+				continue
+			}
+			results = append(results, searchResult{ID: db.ID, Object: "database", Title: notion.PlainText(db.Title), URL: db.URL, LastEditedTime: db.LastEditedTime})
+		}
+		if len(results) >= limit {
+			break
+		}
+	}
+	return map[string]any{"results": results}, nil
+}
+
+type getPageArgs struct {
+	PageID string `json:"page_id"`
+	Offset int    `json:"offset"`
+}
+
+func (t *Toolset) getPageTool() Tool {
+	return Tool{
+		Name:        "get_page",
+		Description: "Read a Notion page as Markdown, with its properties. Long pages are returned in parts; pass next_offset as offset to continue.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"page_id": map[string]any{"type": "string", "description": "The page ID or URL."},
+				"offset":  map[string]any{"type": "integer", "minimum": 0, "description": "Character offset into the Markdown to start from."},
+			},
+			"required":             []any{"page_id"},
+			"additionalProperties": false,
+		},
+		handler: t.getPage,
+	}
+}
+
+func (t *Toolset) getPage(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args getPageArgs
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.PageID) == "" {
+		return nil, invalid("page_id is required")
+	}
+	if args.Offset < 0 {
+		return nil, invalid("offset must not be negative")
+	}
+	pg, err := t.client.GetPage(ctx, strings.TrimSpace(args.PageID))
+	if err != nil {
+		return nil, err
+	}
+	pc, err := notion.ConvertPage(ctx, t.client, pg, t.converterOptions...)
+	if err != nil {
+		return nil, err
+	}
+	md, next := window(pc.Markdown, args.Offset, t.maxChars)
+	out := map[string]any{
+		"id":               pc.ID,
+		"title":            pc.Title,
+		"url":              pc.URL,
+		"last_edited_time": pc.LastEditedTime,
+		"markdown":         md,
+	}
+	if props := propertyTexts(pg.Properties); len(props) > 0 {
+		out["properties"] = props
+	}
+	if next > 0 {
+		out["truncated"] = true
+		out["next_offset"] = next
+	}
+	return out, nil
+}
+
+type queryDatabaseArgs struct {
+	DatabaseID  string              `json:"database_id"`
+	Filter      map[string]any      `json:"filter"`
+	Sorts       []notion.NotionSort `json:"sorts"`
+	Limit       int                 `json:"limit"`
+	StartCursor string              `json:"start_cursor"`
+}
+
+func (t *Toolset) queryDatabaseTool() Tool {
+	return Tool{
+		Name:        "query_database",
+		Description: "List rows of a Notion database with their properties. Call get_database_schema first to learn the property names and types for filters and sorts.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"database_id": map[string]any{"type": "string", "description": "The database ID or URL."},
+				"filter": map[string]any{
+					"type":        "object",
+					"description": `A Notion API database filter, such as {"property": "Status", "status": {"equals": "Done"}}.`,
+				},
+				"sorts": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"property":  map[string]any{"type": "string"},
+							"timestamp": map[string]any{"type": "string", "enum": []any{"created_time", "last_edited_time"}},
+							"direction": map[string]any{"type": "string", "enum": []any{"ascending", "descending"}},
+						},
+						"additionalProperties": false,
+					},
+				},
+				"limit":        map[string]any{"type": "integer", "minimum": 1, "maximum": maxRowLimit, "description": fmt.Sprintf("Maximum number of rows. Defaults to %d.", defaultRowLimit)},
+				"start_cursor": map[string]any{"type": "string", "description": "The next_cursor of a previous call, to get more rows."},
+			},
+			"required":             []any{"database_id"},
+			"additionalProperties": false,
+		},
+		handler: t.queryDatabase,
+	}
+}
+
+type row struct {
+	ID             string            `json:"id"`
+	Title          string            `json:"title"`
+	URL            string            `json:"url"`
+	LastEditedTime string            `json:"last_edited_time"`
+	Properties     map[string]string `json:"properties,omitempty"`
+}
+
+func (t *Toolset) queryDatabase(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args queryDatabaseArgs
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.DatabaseID) == "" {
+		return nil, invalid("database_id is required")
+	}
+	res, err := t.client.QueryDatabase(ctx, strings.TrimSpace(args.DatabaseID), notion.NotionDatabaseQueryRequest{
+		Filter:      args.Filter,
+		Sorts:       args.Sorts,
+		StartCursor: args.StartCursor,
+		PageSize:    clamp(args.Limit, defaultRowLimit, maxRowLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows := []row{}
+	for _, raw := range res.Results {
+		var pg notion.NotionPage
+		if err := json.Unmarshal(raw, &pg); err != nil || pg.Object != "page" {
+			continue
+		}
+		rows = append(rows, row{
+			ID:             pg.ID,
+			Title:          notion.ExtractNotionTitle(pg.Properties),
+			URL:            pg.URL,
+			LastEditedTime: pg.LastEditedTime,
+			Properties:     propertyTexts(pg.Properties),
+		})
+	}
+	out := map[string]any{"rows": rows, "has_more": res.HasMore}
+	if res.HasMore {
+		out["next_cursor"] = res.NextCursor
+	}
+	return out, nil
+}
+
+type schemaArgs struct {
+	DatabaseID string `json:"database_id"`
+}
+
+type schemaProperty struct {
+	Name               string   `json:"name"`
+	Type               string   `json:"type"`
+	Options            []string `json:"options,omitempty"`
+	NumberFormat       string   `json:"number_format,omitempty"`
+	RelationDatabaseID string   `json:"relation_database_id,omitempty"`
+	Rollup             string   `json:"rollup,omitempty"`
+	FormulaType        string   `json:"formula_type,omitempty"`
+}
+
+func (t *Toolset) databaseSchemaTool() Tool {
+	return Tool{
+		Name:        "get_database_schema",
+		Description: "Describe a Notion database: its title, the name, type and allowed options of each property, and a few sample rows. A property's type is the key of its condition in query_database filters.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"database_id": map[string]any{"type": "string", "description": "The database ID or URL."},
+			},
+			"required":             []any{"database_id"},
+			"additionalProperties": false,
+		},
+		handler: t.databaseSchema,
+	}
+}
+
+func (t *Toolset) databaseSchema(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args schemaArgs
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.DatabaseID) == "" {
+		return nil, invalid("database_id is required")
+	}
+	db, err := t.client.GetDatabase(ctx, strings.TrimSpace(args.DatabaseID))
+	if err != nil {
+		return nil, err
+	}
+	// Sample rows show real values and reveal the result types of formulas.
+	var samples []*notion.NotionPage
+	if res, err := t.client.QueryDatabase(ctx, db.ID, notion.NotionDatabaseQueryRequest{PageSize: sampleRows}); err == nil {
+		for _, raw := range res.Results {
+			var pg notion.NotionPage
+			if err := json.Unmarshal(raw, &pg); err == nil && pg.Object == "page" {
+				samples = append(samples, &pg)
+			}
+		}
+	}
+	props := []schemaProperty{}
+	for _, p := range notion.DatabaseSchema(db, samples...) {
+		props = append(props, schemaProperty{
+			Name:               p.Name,
+			Type:               p.Type,
+			Options:            p.Options,
+			NumberFormat:       p.NumberFormat,
+			RelationDatabaseID: p.RelationDatabaseID,
+			Rollup:             p.Rollup,
+			FormulaType:        p.FormulaType,
+		})
+	}
+	rows := []row{}
+	for _, pg := range samples {
+		rows = append(rows, row{
+			ID:             pg.ID,
+			Title:          notion.ExtractNotionTitle(pg.Properties),
+			URL:            pg.URL,
+			LastEditedTime: pg.LastEditedTime,
+			Properties:     propertyTexts(pg.Properties),
+		})
+	}
+	url := db.PublicURL
+	if url == "" {
+		url = db.URL
+	}
+	out := map[string]any{
+		"id":          db.ID,
+		"title":       notion.PlainText(db.Title),
+		"url":         url,
+		"properties":  props,
+		"sample_rows": rows,
+	}
+	if desc := notion.PlainText(db.Description); desc != "" {
+		out["description"] = desc
+	}
+	return out, nil
+}
+
+// propertyTexts renders non-empty, non-title properties as short strings.
+func propertyTexts(props map[string]any) map[string]string {
+	out := make(map[string]string)
+	for name, v := range props {
+		prop, _ := v.(map[string]any)
+		if typ, _ := prop["type"].(string); typ == "title" {
+			continue
+		}
+		if text := notion.PropertyText(prop); text != "" {
+			out[name] = truncate(text, maxPropertyChars)
+		}
+	}
+	return out
+}
+
+// window returns up to n characters of s starting at offset, preferring to
+// end at a line break, and the offset of the rest or 0 when nothing is left.
+func window(s string, offset, n int) (string, int) {
+	runes := []rune(s)
+	if offset >= len(runes) {
+		return "", 0
+	}
+	end := offset + n
+	if end >= len(runes) {
+		return string(runes[offset:]), 0
+	}
+	for i := end - 1; i > offset+n/2; i-- {
+		if runes[i] == '\n' {
+			end = i + 1
+			break
+		}
+	}
+	return string(runes[offset:end]), end
+}
+
+// truncate cuts s to at most n characters, marking the cut with "…".
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+// clamp returns v, or def when v is not positive, capped at max.
+func clamp(v, def, max int) int {
+	if v <= 0 {
+		return def
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
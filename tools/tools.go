@@ -0,0 +1,211 @@
+// Package tools exposes Notion operations as function-calling tools for LLM
+// agents.
+//
+// A Toolset holds ready-made tool definitions, each with a JSON Schema for
+// its arguments, and the handlers that run them against a notion.Client.
+// Handlers return compact JSON sized for a model's context and report
+// failures as structured errors the model can act on, so agents do not need
+// to hand-write wrappers around SearchWorkspace and GetPageContent.
+//
+// By default the tools only read: search_notion, get_page, query_database
+// and get_database_schema. WithWriteTools adds create_page, append_to_page
+// and update_page.
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/retrieve"
+)
+
+// Tool is a function a model can call.
+type Tool struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Parameters is the JSON Schema of the arguments object.
+	Parameters map[string]any `json:"parameters"`
+
+	handler func(ctx context.Context, args json.RawMessage) (any, error)
+}
+
+// Error codes reported by tools.
+const (
+	CodeInvalidArguments = "invalid_arguments"
+	CodeUnknownTool      = "unknown_tool"
+	CodeNotFound         = "not_found"
+	CodeUnauthorized     = "unauthorized"
+	CodeRateLimited      = "rate_limited"
+	CodeUpstream         = "upstream_error"
+)
+
+// Error is a tool failure in a form a model can act on.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// Option configures a Toolset.
+type Option func(*Toolset)
+
+// WithMaxChars bounds the Markdown returned by get_page, in characters. Longer
+// pages are cut, and the result tells the model the offset to continue from.
+// The default is 8000.
+func WithMaxChars(n int) Option {
+	return func(t *Toolset) {
+		if n > 0 {
+			t.maxChars = n
+		}
+	}
+}
+
+// WithConverterOptions sets the options used to render pages.
+func WithConverterOptions(opts ...notion.ConverterOption) Option {
+	return func(t *Toolset) {
+		t.converterOptions = opts
+	}
+}
+
+// WithRetriever makes search_notion use r, which can search page bodies,
+// instead of the Notion search API, which matches mostly titles.
+func WithRetriever(r *retrieve.Retriever) Option {
+	return func(t *Toolset) {
+		t.retriever = r
+	}
+}
+
+// WithWriteTools adds tools that change the workspace: create_page,
+// append_to_page and update_page. Only enable them for agents that should
+// edit Notion, with an integration whose access is limited to match.
+func WithWriteTools() Option {
+	return func(t *Toolset) {
+		t.write = true
+	}
+}
+
+// Toolset is a set of Notion tools bound to a client.
+type Toolset struct {
+	client           *notion.Client
+	maxChars         int
+	converterOptions []notion.ConverterOption
+	retriever        *retrieve.Retriever
+	write            bool
+	tools            []Tool
+}
+
+// New returns the Notion tools for client.
+func New(client *notion.Client, opts ...Option) *Toolset {
+	t := &Toolset{client: client, maxChars: 8000}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.tools = []Tool{
+		t.searchTool(),
+		t.getPageTool(),
+		t.queryDatabaseTool(),
+		t.databaseSchemaTool(),
+	}
+	if t.write {
+		t.tools = append(t.tools, t.createPageTool(), t.appendTool(), t.updatePageTool())
+	}
+	return t
+}
+
+// Tools returns the tool definitions.
+func (t *Toolset) Tools() []Tool {
+	return t.tools
+}
+
+// OpenAIFunctions returns the tool definitions in the format of the
+// "tools" parameter of the OpenAI chat completions API.
+func (t *Toolset) OpenAIFunctions() []map[string]any {
+	out := make([]map[string]any, len(t.tools))
+	for i, tool := range t.tools {
+		out[i] = map[string]any{
+			"type": "function",
+			"function": map[string]any{
+				"name":        tool.Name,
+				"description": tool.Description,
+				"parameters":  tool.Parameters,
+			},
+		}
+	}
+	return out
+}
+
+// Call runs the named tool with JSON arguments and returns its result as
+// JSON to hand back to the model. On failure the returned JSON is
+// {"error": {"code": ..., "message": ...}} and the error is an *Error with
+// the same content.
+func (t *Toolset) Call(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	result, err := t.call(ctx, name, args)
+	if err == nil {
+		var out []byte
+		if out, err = json.Marshal(result); err == nil {
+			return string(out), nil
+		}
+		err = fmt.Errorf("failed to encode result: %w", err)
+	}
+	var te *Error
+	if !errors.As(err, &te) {
+		te = classify(err)
+	}
+	out, _ := json.Marshal(map[string]any{"error": te})
+	return string(out), te
+}
+
+func (t *Toolset) call(ctx context.Context, name string, args json.RawMessage) (any, error) {
+	for _, tool := range t.tools {
+		if tool.Name == name {
+			if len(args) == 0 {
+				args = json.RawMessage("{}")
+			}
+			return tool.handler(ctx, args)
+		}
+	}
+	return nil, &Error{Code: CodeUnknownTool, Message: fmt.Sprintf("no tool named %q", name)}
+}
+
+// decodeArgs unmarshals tool arguments, rejecting unknown fields so typos in
+// argument names are reported to the model instead of silently ignored.
+func decodeArgs(args json.RawMessage, v any) error {
+	dec := json.NewDecoder(strings.NewReader(string(args)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return &Error{Code: CodeInvalidArguments, Message: err.Error()}
+	}
+	return nil
+}
+
+// classify turns a client error into an Error, choosing the code from the
+// API's response when the error carries one.
+func classify(err error) *Error {
+	code := CodeUpstream
+	var apiErr *notion.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusNotFound:
+			code = CodeNotFound
+		case apiErr.StatusCode == http.StatusBadRequest:
+			code = CodeInvalidArguments
+		case apiErr.StatusCode == http.StatusUnauthorized, apiErr.StatusCode == http.StatusForbidden:
+			code = CodeUnauthorized
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			code = CodeRateLimited
+		}
+	}
+	return &Error{Code: code, Message: err.Error()}
+}
+
+func invalid(format string, args ...any) error {
+	return &Error{Code: CodeInvalidArguments, Message: fmt.Sprintf(format, args...)}
+}
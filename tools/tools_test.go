@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/internal/notiontest"
+)
+
+func call(t *testing.T, ts *Toolset, name, args string) map[string]any {
+	t.Helper()
+	out, err := ts.Call(context.Background(), name, json.RawMessage(args))
+	if err != nil {
+		t.Fatalf("%s(%s): %v", name, args, err)
+	}
+	var v map[string]any
+	if err := json.Unmarshal([]byte(out), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestTools(t *testing.T) {
+	srv := notiontest.New(t)
+	srv.AddPage(notiontest.Page("guide", "On-call Guide"))
+	srv.SetChildren("guide",
+		notiontest.TextBlock("p1", "paragraph", strings.Repeat("a", 30)),
+		notiontest.TextBlock("p2", "paragraph", strings.Repeat("b", 30)),
+	)
+	srv.AddDatabase(notiontest.Database("db", "Incidents", map[string]any{
+		"Name":   map[string]any{"type": "title", "title": map[string]any{}},
+		"Status": map[string]any{"type": "select", "select": map[string]any{"options": []any{map[string]any{"name": "Open"}, map[string]any{"name": "Closed"}}}},
+	}), notiontest.Row("r1", map[string]any{
+		"Name":   notiontest.TitleProperty("Outage"),
+		"Status": notiontest.SelectProperty("Open"),
+	}))
+	client := notion.NewClient("secret", "", notion.WithHTTPClient(srv.HTTPClient()))
+	ts := New(client, WithMaxChars(40))
+
+	if got := len(ts.OpenAIFunctions()); got != 4 {
+		t.Fatalf("expected 4 tools, got %d", got)
+	}
+	for _, tool := range ts.Tools() {
+		if _, err := json.Marshal(tool.Parameters); err != nil || tool.Parameters["type"] != "object" {
+			t.Fatalf("tool %s has an invalid schema", tool.Name)
+		}
+	}
+
+	res := call(t, ts, "search_notion", `{"query": "incidents"}`)
+	results := res["results"].([]any)
+	if len(results) != 1 || results[0].(map[string]any)["object"] != "database" {
+		t.Fatalf("unexpected search results: %v", res)
+	}
+
+	page := call(t, ts, "get_page", `{"page_id": "guide"}`)
+	if page["title"] != "On-call Guide" || page["markdown"] != strings.Repeat("a", 30)+"\n\n" || page["next_offset"] != float64(32) {
+		t.Fatalf("unexpected first part: %v", page)
+	}
+	page = call(t, ts, "get_page", `{"page_id": "guide", "offset": 32}`)
+	if page["markdown"] != strings.Repeat("b", 30) || page["truncated"] != nil {
+		t.Fatalf("unexpected second part: %v", page)
+	}
+
+	rows := call(t, ts, "query_database", `{"database_id": "db", "sorts": [{"property": "Status", "direction": "ascending"}]}`)
+	if got, _ := json.Marshal(rows["rows"]); !strings.Contains(string(got), `"title":"Outage"`) || !strings.Contains(string(got), `"properties":{"Status":"Open"}`) {
+		t.Fatalf("unexpected rows: %s", got)
+	}
+
+	schema := call(t, ts, "get_database_schema", `{"database_id": "db"}`)
+	if got, _ := json.Marshal(schema["properties"]); string(got) != `[{"name":"Name","type":"title"},{"name":"Status","options":["Open","Closed"],"type":"select"}]` {
+		t.Fatalf("unexpected schema: %s", got)
+	}
+}
+
+func TestToolErrors(t *testing.T) {
+	srv := notiontest.New(t)
+	client := notion.NewClient("secret", "", notion.WithHTTPClient(srv.HTTPClient()))
+	srv.SetFailure("GET /v1/pages/busy", notiontest.Failure{Status: 429, Code: "rate_limited"})
+	srv.SetFailure("GET /v1/pages/flaky", notiontest.Failure{Status: 502, Code: "bad_gateway", Message: "upstream said status=404"})
+	ts := New(client)
+	tests := []struct {
+		name, args, code string
+	}{
+		{"get_page", `{"page_id": "missing"}`, CodeNotFound},
+		{"get_page", `{"page_id": "busy"}`, CodeRateLimited},
+		{"get_page", `{"page_id": "flaky"}`, CodeUpstream},
+		{"get_page", `{"id": "x"}`, CodeInvalidArguments},
+		{"search_notion", `{}`, CodeInvalidArguments},
+		{"delete_page", `{}`, CodeUnknownTool},
+	}
+	for _, tt := range tests {
+		out, err := ts.Call(context.Background(), tt.name, json.RawMessage(tt.args))
+		var te *Error
+		if !errors.As(err, &te) || te.Code != tt.code {
+			t.Errorf("%s(%s): expected %s, got %v", tt.name, tt.args, tt.code, err)
+			continue
+		}
+		if !strings.HasPrefix(out, `{"error":{"code":"`+tt.code+`"`) {
+			t.Errorf("%s(%s): unexpected output %s", tt.name, tt.args, out)
+		}
+	}
+}
+
+func TestWriteTools(t *testing.T) {
+	srv := notiontest.New(t)
+	srv.AddPage(notiontest.Page("notes", "Notes"))
+	srv.AddDatabase(notiontest.Database("db", "Incidents", map[string]any{
+		"Name":     map[string]any{"type": "title", "title": map[string]any{}},
+		"Status":   map[string]any{"type": "select", "select": map[string]any{}},
+		"Severity": map[string]any{"type": "number", "number": map[string]any{}},
+	}))
+	client := notion.NewClient("secret", "", notion.WithHTTPClient(srv.HTTPClient()))
+	if got := len(New(client).Tools()); got != 4 {
+		t.Fatalf("expected write tools to be off by default, got %d tools", got)
+	}
+	ts := New(client, WithWriteTools())
+
+	created := call(t, ts, "create_page", `{"parent_id": "notes", "title": "Retro", "content": "# Wins\n\n- Shipped\n- [x] Tested\n\nMore to come."}`)
+	page := call(t, ts, "get_page", `{"page_id": "`+created["id"].(string)+`"}`)
+	if page["title"] != "Retro" || page["markdown"] != "# Wins\n\n- Shipped\n- [x] Tested\n\nMore to come." {
+		t.Fatalf("unexpected created page: %v", page)
+	}
+	call(t, ts, "append_to_page", `{"page_id": "`+created["id"].(string)+"\", \"content\": \"```go\\nx := 1\\n```\"}")
+	page = call(t, ts, "get_page", `{"page_id": "`+created["id"].(string)+`"}`)
+	if !strings.HasSuffix(page["markdown"].(string), "```go\nx := 1\n```") {
+		t.Fatalf("unexpected page after append: %v", page["markdown"])
+	}
+
+	row := call(t, ts, "create_page", `{"parent_id": "db", "title": "Outage", "properties": {"Status": "Open", "Severity": 2}}`)
+	call(t, ts, "update_page", `{"page_id": "`+row["id"].(string)+`", "properties": {"Status": "Closed"}}`)
+	res := call(t, ts, "query_database", `{"database_id": "db"}`)
+	rows := res["rows"].([]any)
+	if len(rows) != 1 {
+		t.Fatalf("unexpected rows: %v", res)
+	}
+	r := rows[0].(map[string]any)
+	props := r["properties"].(map[string]any)
+	if r["title"] != "Outage" || props["Status"] != "Closed" || props["Severity"] != "2" {
+		t.Fatalf("unexpected row: %v", r)
+	}
+
+	_, err := ts.Call(context.Background(), "update_page", json.RawMessage(`{"page_id": "`+row["id"].(string)+`", "properties": {"Owner": "me"}}`))
+	var te *Error
+	if !errors.As(err, &te) || te.Code != CodeInvalidArguments || !strings.Contains(te.Message, "get_database_schema") {
+		t.Fatalf("expected an unknown property error, got %v", err)
+	}
+}
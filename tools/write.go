@@ -0,0 +1,264 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	notion "github.com/openai/notion-go-agents"
+)
+
+// propertiesSchema describes the properties argument of the write tools.
+var propertiesSchema = map[string]any{
+	"type":        "object",
+	"description": "Property values by name, as plain JSON: a string for title, text, select, status, URL, email, phone and date (ISO 8601) properties, a number, a boolean for checkboxes, and an array of strings for multi-select options or related page IDs.",
+}
+
+type createPageArgs struct {
+	ParentID   string         `json:"parent_id"`
+	Title      string         `json:"title"`
+	Content    string         `json:"content"`
+	Properties map[string]any `json:"properties"`
+}
+
+func (t *Toolset) createPageTool() Tool {
+	return Tool{
+		Name:        "create_page",
+		Description: "Create a Notion page below a page, or a row in a database. Call get_database_schema first to learn a database's property names and types.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"parent_id":  map[string]any{"type": "string", "description": "The ID or URL of the parent page or database."},
+				"title":      map[string]any{"type": "string", "description": "The page title."},
+				"content":    map[string]any{"type": "string", "description": "The page body as Markdown."},
+				"properties": propertiesSchema,
+			},
+			"required":             []any{"parent_id", "title"},
+			"additionalProperties": false,
+		},
+		handler: t.createPage,
+	}
+}
+
+func (t *Toolset) createPage(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args createPageArgs
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	parentID := strings.TrimSpace(args.ParentID)
+	if parentID == "" {
+		return nil, invalid("parent_id is required")
+	}
+	if strings.TrimSpace(args.Title) == "" {
+		return nil, invalid("title is required")
+	}
+	req := notion.NotionCreatePageRequest{Children: markdownBlocks(args.Content)}
+	if db, err := t.client.GetDatabase(ctx, parentID); err == nil {
+		props, err := propertyValues(db.Properties, args.Properties)
+		if err != nil {
+			return nil, err
+		}
+		for name, v := range db.Properties {
+			if prop, _ := v.(map[string]any); prop["type"] == "title" {
+				props[name] = map[string]any{"title": richText(args.Title)}
+			}
+		}
+		req.Parent = map[string]any{"database_id": db.ID}
+		req.Properties = props
+	} else if code := classify(err).Code; code != CodeNotFound && code != CodeInvalidArguments {
+		// The API answers 404 or 400 when the ID is a page's.
+		return nil, err
+	} else {
+		if len(args.Properties) > 0 {
+			return nil, invalid("properties can only be set on database rows")
+		}
+		pg, err := t.client.GetPage(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		req.Parent = map[string]any{"page_id": pg.ID}
+		req.Properties = map[string]any{"title": map[string]any{"title": richText(args.Title)}}
+	}
+	pg, err := t.client.CreatePage(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"id": pg.ID, "url": pg.URL}, nil
+}
+
+type appendArgs struct {
+	PageID  string `json:"page_id"`
+	Content string `json:"content"`
+}
+
+func (t *Toolset) appendTool() Tool {
+	return Tool{
+		Name:        "append_to_page",
+		Description: "Add Markdown content to the end of a Notion page.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"page_id": map[string]any{"type": "string", "description": "The page ID or URL."},
+				"content": map[string]any{"type": "string", "description": "The content to add, as Markdown."},
+			},
+			"required":             []any{"page_id", "content"},
+			"additionalProperties": false,
+		},
+		handler: t.appendToPage,
+	}
+}
+
+func (t *Toolset) appendToPage(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args appendArgs
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.PageID) == "" {
+		return nil, invalid("page_id is required")
+	}
+	blocks := markdownBlocks(args.Content)
+	if len(blocks) == 0 {
+		return nil, invalid("content is empty")
+	}
+	if err := t.client.AppendBlockChildren(ctx, strings.TrimSpace(args.PageID), blocks); err != nil {
+		return nil, err
+	}
+	return map[string]any{"appended_blocks": len(blocks)}, nil
+}
+
+type updatePageArgs struct {
+	PageID     string         `json:"page_id"`
+	Properties map[string]any `json:"properties"`
+}
+
+func (t *Toolset) updatePageTool() Tool {
+	return Tool{
+		Name:        "update_page",
+		Description: "Set properties of a Notion page or database row. Properties not given are left unchanged.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"page_id":    map[string]any{"type": "string", "description": "The page ID or URL."},
+				"properties": propertiesSchema,
+			},
+			"required":             []any{"page_id", "properties"},
+			"additionalProperties": false,
+		},
+		handler: t.updatePage,
+	}
+}
+
+func (t *Toolset) updatePage(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args updatePageArgs
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.PageID) == "" {
+		return nil, invalid("page_id is required")
+	}
+	if len(args.Properties) == 0 {
+		return nil, invalid("properties is required")
+	}
+	pg, err := t.client.GetPage(ctx, strings.TrimSpace(args.PageID))
+	if err != nil {
+		return nil, err
+	}
+	// Pages outside databases have only their title.
+	schema := pg.Properties
+	if dbID, _ := pg.Parent["database_id"].(string); dbID != "" {
+		db, err := t.client.GetDatabase(ctx, dbID)
+		if err != nil {
+			return nil, err
+		}
+		schema = db.Properties
+	}
+	props, err := propertyValues(schema, args.Properties)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := t.client.UpdatePageProperties(ctx, pg.ID, props)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"id": updated.ID, "url": updated.URL, "last_edited_time": updated.LastEditedTime}, nil
+}
+
+// propertyValues converts the plain JSON values a model passes into the
+// property values the API expects, using the types in schema.
+func propertyValues(schema map[string]any, values map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(values))
+	for name, v := range values {
+		prop, ok := schema[name].(map[string]any)
+		if !ok {
+			return nil, invalid("unknown property %q; call get_database_schema for the property names", name)
+		}
+		typ, _ := prop["type"].(string)
+		value, err := propertyValue(typ, v)
+		if err != nil {
+			return nil, invalid("property %q: %v", name, err)
+		}
+		out[name] = map[string]any{typ: value}
+	}
+	return out, nil
+}
+
+func propertyValue(typ string, v any) (any, error) {
+	switch typ {
+	case "title", "rich_text":
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s values are strings", typ)
+		}
+		return richText(s), nil
+	case "select", "status":
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s values are option names", typ)
+		}
+		return map[string]any{"name": s}, nil
+	case "url", "email", "phone_number":
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s values are strings", typ)
+		}
+		return s, nil
+	case "date":
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("date values are ISO 8601 strings")
+		}
+		return map[string]any{"start": s}, nil
+	case "number":
+		n, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("number values are numbers")
+		}
+		return n, nil
+	case "checkbox":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("checkbox values are true or false")
+		}
+		return b, nil
+	case "multi_select", "relation":
+		items, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%s values are arrays of strings", typ)
+		}
+		key := "name"
+		if typ == "relation" {
+			key = "id"
+		}
+		out := make([]any, 0, len(items))
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s values are arrays of strings", typ)
+			}
+			out = append(out, map[string]any{key: s})
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("%s properties cannot be set", typ)
+}
@@ -4,9 +4,11 @@ import "encoding/json"
 
 // NotionSearchRequest describes parameters for the search API.
 type NotionSearchRequest struct {
-	Query  string           `json:"query,omitempty"`
-	Filter *NotionObjFilter `json:"filter,omitempty"`
-	Sort   *NotionSort      `json:"sort,omitempty"`
+	Query       string           `json:"query,omitempty"`
+	Filter      *NotionObjFilter `json:"filter,omitempty"`
+	Sort        *NotionSort      `json:"sort,omitempty"`
+	StartCursor string           `json:"start_cursor,omitempty"`
+	PageSize    int              `json:"page_size,omitempty"`
 }
 
 // NotionObjFilter filters search results by object type.
@@ -16,7 +18,9 @@ type NotionObjFilter struct {
 }
 
 // NotionSort specifies sort options for search and database queries.
+// Database queries can sort by Property instead of Timestamp.
 type NotionSort struct {
+	Property  string `json:"property,omitempty"`
 	Direction string `json:"direction,omitempty"`
 	Timestamp string `json:"timestamp,omitempty"`
 }
@@ -47,6 +51,20 @@ type NotionPage struct {
 	PublicURL      string         `json:"public_url"`
 }
 
+// NotionDatabase represents a database object and its property schema.
+type NotionDatabase struct {
+	NotionPageRef
+	Title          []any          `json:"title"`
+	Description    []any          `json:"description"`
+	CreatedTime    string         `json:"created_time"`
+	LastEditedTime string         `json:"last_edited_time"`
+	Parent         map[string]any `json:"parent"`
+	Archived       bool           `json:"archived"`
+	IsInline       bool           `json:"is_inline"`
+	Properties     map[string]any `json:"properties"`
+	PublicURL      string         `json:"public_url"`
+}
+
 // NotionDatabaseQueryRequest describes a database query.
 type NotionDatabaseQueryRequest struct {
 	Filter      map[string]any `json:"filter,omitempty"`
@@ -55,6 +73,15 @@ type NotionDatabaseQueryRequest struct {
 	PageSize    int            `json:"page_size,omitempty"`
 }
 
+// NotionCreatePageRequest describes a page to create. Parent is
+// {"page_id": id} for a page below another page, or {"database_id": id} for
+// a database row, whose Properties must then match the database's schema.
+type NotionCreatePageRequest struct {
+	Parent     map[string]any   `json:"parent"`
+	Properties map[string]any   `json:"properties"`
+	Children   []map[string]any `json:"children,omitempty"`
+}
+
 // NotionDatabaseQueryResponse holds results from a database query.
 type NotionDatabaseQueryResponse struct {
 	Object     string            `json:"object"`
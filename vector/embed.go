@@ -0,0 +1,85 @@
+// Package vector adds semantic search over Notion pages: an Embedder
+// interface with a deterministic hashing implementation and an
+// OpenAI-compatible HTTP client, and an in-memory Index that ranks chunks by
+// cosine similarity and can be saved to and loaded from disk.
+//
+// Notion's own search matches mostly on titles; indexing chunk text finds
+// pages by what they say.
+package vector
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Embedder turns texts into vectors. Implementations return one vector per
+// text, in order, and every vector has the same length.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// HashEmbedder embeds texts by hashing their lowercased words into a fixed
+// number of dimensions. It needs no model or network and always returns the
+// same vector for the same text, which makes it suitable for tests and as a
+// lexical fallback; it captures word overlap, not meaning.
+type HashEmbedder struct {
+	dims int
+}
+
+// NewHashEmbedder returns a HashEmbedder producing vectors of dims
+// dimensions, or 256 when dims is not positive.
+func NewHashEmbedder(dims int) *HashEmbedder {
+	if dims <= 0 {
+		dims = 256
+	}
+	return &HashEmbedder{dims: dims}
+}
+
+// Embed implements Embedder.
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, e.dims)
+		for _, word := range words(text) {
+			h := fnv.New64a()
+			h.Write([]byte(word))
+			sum := h.Sum64()
+			// The top bit picks the sign so unrelated words colliding in a
+			// dimension tend to cancel out rather than add up.
+			if sum>>63 == 0 {
+				v[sum%uint64(e.dims)]++
+			} else {
+				v[sum%uint64(e.dims)]--
+			}
+		}
+		out[i] = normalize(v)
+	}
+	return out, nil
+}
+
+// words splits text into lowercase runs of letters and digits.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// normalize scales v to unit length in place and returns it. A zero vector is
+// returned unchanged.
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
@@ -0,0 +1,253 @@
+package vector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	notion "github.com/openai/notion-go-agents"
+	"github.com/openai/notion-go-agents/chunk"
+	"github.com/openai/notion-go-agents/internal/atomicfile"
+)
+
+// Document is a piece of a page stored in an Index.
+type Document struct {
+	// ID identifies the document within the index, "<page ID>#<chunk index>"
+	// for documents made by Documents.
+	ID             string   `json:"id"`
+	PageID         string   `json:"page_id"`
+	Title          string   `json:"title"`
+	URL            string   `json:"url"`
+	Breadcrumb     []string `json:"breadcrumb,omitempty"`
+	Text           string   `json:"text"`
+	BlockIDs       []string `json:"block_ids,omitempty"`
+	LastEditedTime string   `json:"last_edited_time,omitempty"`
+}
+
+// Documents turns the chunks of a page into documents carrying the page's
+// title, URL and edit time.
+func Documents(pc *notion.PageContent, chunks []chunk.Chunk) []Document {
+	docs := make([]Document, len(chunks))
+	for i, c := range chunks {
+		docs[i] = Document{
+			ID:             fmt.Sprintf("%s#%d", pc.ID, c.Index),
+			PageID:         pc.ID,
+			Title:          pc.Title,
+			URL:            pc.URL,
+			Breadcrumb:     c.Breadcrumb,
+			Text:           c.Text,
+			BlockIDs:       c.BlockIDs,
+			LastEditedTime: pc.LastEditedTime,
+		}
+	}
+	return docs
+}
+
+// Result is a document found by Search with its cosine similarity to the
+// query.
+type Result struct {
+	Document
+	Score float64
+}
+
+// Index holds documents with their embeddings in memory and ranks them by
+// cosine similarity to a query. It is safe for concurrent use.
+type Index struct {
+	embedder Embedder
+
+	mu   sync.RWMutex
+	docs map[string]*entry
+}
+
+type entry struct {
+	Document
+	Vector []float32 `json:"vector"`
+}
+
+// NewIndex returns an empty index that embeds documents and queries with
+// embedder.
+func NewIndex(embedder Embedder) *Index {
+	return &Index{embedder: embedder, docs: make(map[string]*entry)}
+}
+
+// Len returns the number of documents in the index.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Add embeds docs and adds them to the index, replacing documents with the
+// same ID. The text embedded is the document's breadcrumb followed by its
+// text, as in chunk.Chunk.ContextText.
+func (ix *Index) Add(ctx context.Context, docs ...Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = contextText(doc)
+	}
+	vecs, err := ix.embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed documents: %w", err)
+	}
+	if len(vecs) != len(docs) {
+		return fmt.Errorf("embedder returned %d vectors for %d documents", len(vecs), len(docs))
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for i, doc := range docs {
+		ix.docs[doc.ID] = &entry{Document: doc, Vector: normalize(vecs[i])}
+	}
+	return nil
+}
+
+// AddPage replaces the documents of a page with chunks of its Markdown cut
+// by c, or by a default chunk.Chunker when c is nil.
+func (ix *Index) AddPage(ctx context.Context, pc *notion.PageContent, c *chunk.Chunker) error {
+	if c == nil {
+		c = chunk.New()
+	}
+	docs := Documents(pc, c.SplitPageContent(pc))
+	// Embed first so a failure leaves the page's previous documents in place.
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = contextText(doc)
+	}
+	var vecs [][]float32
+	if len(texts) > 0 {
+		var err error
+		if vecs, err = ix.embedder.Embed(ctx, texts); err != nil {
+			return fmt.Errorf("failed to embed page %s: %w", pc.ID, err)
+		}
+		if len(vecs) != len(docs) {
+			return fmt.Errorf("embedder returned %d vectors for %d documents", len(vecs), len(docs))
+		}
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.removePage(pc.ID)
+	for i, doc := range docs {
+		ix.docs[doc.ID] = &entry{Document: doc, Vector: normalize(vecs[i])}
+	}
+	return nil
+}
+
+// RemovePage removes the documents of a page and returns how many were
+// removed.
+func (ix *Index) RemovePage(pageID string) int {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return ix.removePage(pageID)
+}
+
+func (ix *Index) removePage(pageID string) int {
+	n := 0
+	for id, e := range ix.docs {
+		if notion.NormalizeID(e.PageID) == notion.NormalizeID(pageID) {
+			delete(ix.docs, id)
+			n++
+		}
+	}
+	return n
+}
+
+// Search embeds query and returns the k documents most similar to it, best
+// first. Documents with equal scores are ordered by ID.
+func (ix *Index) Search(ctx context.Context, query string, k int) ([]Result, error) {
+	vecs, err := ix.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(vecs) != 1 {
+		return nil, fmt.Errorf("embedder returned %d vectors for one query", len(vecs))
+	}
+	q := normalize(vecs[0])
+
+	ix.mu.RLock()
+	results := make([]Result, 0, len(ix.docs))
+	for _, e := range ix.docs {
+		if len(e.Vector) != len(q) {
+			ix.mu.RUnlock()
+			return nil, fmt.Errorf("query has %d dimensions but document %s has %d", len(q), e.ID, len(e.Vector))
+		}
+		results = append(results, Result{Document: e.Document, Score: dot(q, e.Vector)})
+	}
+	ix.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+	if k > 0 && len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// indexFile is the on-disk form of an Index.
+type indexFile struct {
+	Documents []*entry `json:"documents"`
+}
+
+// Save writes the documents and their embeddings to path as JSON, through a
+// temporary file so a crash never leaves a partial index.
+func (ix *Index) Save(path string) error {
+	ix.mu.RLock()
+	f := indexFile{Documents: make([]*entry, 0, len(ix.docs))}
+	for _, e := range ix.docs {
+		f.Documents = append(f.Documents, e)
+	}
+	ix.mu.RUnlock()
+	sort.Slice(f.Documents, func(i, j int) bool { return f.Documents[i].ID < f.Documents[j].ID })
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// LoadIndex reads an index written by Save. The embedder must be the one the
+// index was built with, or queries will not be comparable to the stored
+// vectors. A missing file yields an empty index.
+func LoadIndex(path string, embedder Embedder) (*Index, error) {
+	ix := NewIndex(embedder)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ix, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	var f indexFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode index: %w", err)
+	}
+	for _, e := range f.Documents {
+		ix.docs[e.ID] = e
+	}
+	return ix, nil
+}
+
+func contextText(doc Document) string {
+	return chunk.Chunk{Breadcrumb: doc.Breadcrumb, Text: doc.Text}.ContextText()
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
@@ -0,0 +1,144 @@
+package vector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIOption configures an OpenAIEmbedder.
+type OpenAIOption func(*OpenAIEmbedder)
+
+// WithBaseURL sets the API base URL, such as "http://localhost:11434/v1" for
+// a local server implementing the OpenAI embeddings endpoint. The default is
+// "https://api.openai.com/v1".
+func WithBaseURL(url string) OpenAIOption {
+	return func(e *OpenAIEmbedder) {
+		e.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for requests.
+func WithHTTPClient(h *http.Client) OpenAIOption {
+	return func(e *OpenAIEmbedder) {
+		e.httpClient = h
+	}
+}
+
+// WithBatchSize sets the maximum number of texts sent in one request. The
+// default is 64.
+func WithBatchSize(n int) OpenAIOption {
+	return func(e *OpenAIEmbedder) {
+		if n > 0 {
+			e.batchSize = n
+		}
+	}
+}
+
+// WithDimensions asks models that support it for vectors of n dimensions.
+func WithDimensions(n int) OpenAIOption {
+	return func(e *OpenAIEmbedder) {
+		e.dimensions = n
+	}
+}
+
+// OpenAIEmbedder calls an OpenAI-compatible /embeddings endpoint.
+type OpenAIEmbedder struct {
+	httpClient *http.Client
+	apiKey     string
+	model      string
+	baseURL    string
+	batchSize  int
+	dimensions int
+}
+
+// NewOpenAIEmbedder returns an embedder using model. The API key is sent as a
+// bearer token unless it is empty, which local servers usually accept.
+func NewOpenAIEmbedder(apiKey, model string, opts ...OpenAIOption) *OpenAIEmbedder {
+	e := &OpenAIEmbedder{
+		apiKey:    apiKey,
+		model:     model,
+		baseURL:   "https://api.openai.com/v1",
+		batchSize: 64,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.httpClient == nil {
+		e.httpClient = &http.Client{Timeout: 60 * time.Second}
+	}
+	return e
+}
+
+type embeddingRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions,omitempty"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed implements Embedder, sending texts in batches.
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += e.batchSize {
+		batch := texts[start:min(start+e.batchSize, len(texts))]
+		vecs, err := e.embedBatch(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, vecs...)
+	}
+	return out, nil
+}
+
+func (e *OpenAIEmbedder) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	bts, err := json.Marshal(embeddingRequest{Model: e.model, Input: texts, Dimensions: e.dimensions})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal embedding request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/embeddings", bytes.NewReader(bts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("embedding request failed: status=%d body=%s", resp.StatusCode, string(body))
+	}
+	var er embeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&er); err != nil {
+		return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+	vecs := make([][]float32, len(texts))
+	for _, d := range er.Data {
+		if d.Index < 0 || d.Index >= len(vecs) {
+			return nil, fmt.Errorf("embedding response has out of range index %d", d.Index)
+		}
+		vecs[d.Index] = d.Embedding
+	}
+	for i, v := range vecs {
+		if v == nil {
+			return nil, fmt.Errorf("embedding response is missing input %d", i)
+		}
+	}
+	return vecs, nil
+}
@@ -0,0 +1,134 @@
+package vector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	notion "github.com/openai/notion-go-agents"
+)
+
+func TestHashEmbedder(t *testing.T) {
+	e := NewHashEmbedder(64)
+	vecs, err := e.Embed(context.Background(), []string{"Rotate the API keys", "rotate the api KEYS!", "Lunch menu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vecs[0]) != 64 || !reflect.DeepEqual(vecs[0], vecs[1]) {
+		t.Fatalf("expected equal vectors for the same words: %v %v", vecs[0], vecs[1])
+	}
+	if s := dot(vecs[0], vecs[2]); s > 0.5 {
+		t.Fatalf("unrelated texts too similar: %f", s)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	ctx := context.Background()
+	ix := NewIndex(NewHashEmbedder(256))
+	pages := []*notion.PageContent{
+		{ID: "a", Title: "Security", URL: "https://www.notion.so/a", Markdown: "## Keys\n\nRotate the API keys every quarter.\n\n## Laptops\n\nEncrypt laptop disks."},
+		{ID: "b", Title: "Office", URL: "https://www.notion.so/b", Markdown: "The lunch menu changes weekly."},
+	}
+	for _, pc := range pages {
+		if err := ix.AddPage(ctx, pc, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ix.Len() != 3 {
+		t.Fatalf("expected 3 documents, got %d", ix.Len())
+	}
+	res, err := ix.Search(ctx, "how often are api keys rotated", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].ID != "a#0" || res[0].URL != "https://www.notion.so/a" || !reflect.DeepEqual(res[0].Breadcrumb, []string{"Security", "Keys"}) {
+		t.Fatalf("unexpected results: %+v", res)
+	}
+
+	// Re-adding a page replaces its documents.
+	pages[0].Markdown = "Nothing here yet."
+	if err := ix.AddPage(ctx, pages[0], nil); err != nil {
+		t.Fatal(err)
+	}
+	if ix.Len() != 2 {
+		t.Fatalf("expected the old chunks to be replaced, got %d documents", ix.Len())
+	}
+
+	path := filepath.Join(t.TempDir(), "index.json")
+	if err := ix.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadIndex(path, NewHashEmbedder(256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := ix.Search(ctx, "lunch", 0)
+	got, err := loaded.Search(ctx, "lunch", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("loaded index differs:\n%+v\nwant:\n%+v", got, want)
+	}
+	// An index queried with a different embedder reports the mismatch.
+	small, err := LoadIndex(path, NewHashEmbedder(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := small.Search(ctx, "lunch", 1); err == nil {
+		t.Fatal("expected a dimension mismatch error")
+	}
+	if n := loaded.RemovePage("b"); n != 1 || loaded.Len() != 1 {
+		t.Fatalf("unexpected removal: %d, %d left", n, loaded.Len())
+	}
+}
+
+func TestOpenAIEmbedder(t *testing.T) {
+	var batches [][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		var req embeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "text-embedding-3-small" {
+			http.Error(w, "bad body", http.StatusBadRequest)
+			return
+		}
+		batches = append(batches, req.Input)
+		// Answer out of order, as the API allows.
+		var resp embeddingResponse
+		resp.Data = make([]struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}, len(req.Input))
+		for i, text := range req.Input {
+			d := &resp.Data[len(req.Input)-1-i]
+			d.Index = i
+			d.Embedding = []float32{float32(len(text)), 1}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	e := NewOpenAIEmbedder("sk-test", "text-embedding-3-small", WithBaseURL(srv.URL+"/v1/"), WithBatchSize(2))
+	vecs, err := e.Embed(context.Background(), []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Fatalf("unexpected batches: %v", batches)
+	}
+	if !reflect.DeepEqual(vecs, [][]float32{{1, 1}, {2, 1}, {3, 1}}) {
+		t.Fatalf("unexpected vectors: %v", vecs)
+	}
+
+	_, err = NewOpenAIEmbedder("wrong", "m", WithBaseURL(srv.URL+"/v1")).Embed(context.Background(), []string{"a"})
+	if err == nil {
+		t.Fatal("expected an error for a rejected request")
+	}
+}